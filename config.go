@@ -4,10 +4,49 @@ import (
 	"sync"
 )
 
+// StackPolicy specifies when creating or wrapping an error records a stack trace.
+type StackPolicy int
+
+const (
+	// StackDeepest records a stack only if there is no error of this package on the chain yet,
+	// so that only the deepest error carries a stack. It is the default policy.
+	StackDeepest StackPolicy = iota
+	// StackEveryWrap records a stack on every error and every Wrap.
+	StackEveryWrap
+	// StackAfterForeign records a stack if the wrapped error is not an error of this package,
+	// even if the chain below it already carries a stack, e.g. after the error crossed a goroutine or a queue
+	// inside a foreign wrapper.
+	StackAfterForeign
+	// StackNever never records a stack.
+	StackNever
+)
+
 // Config represents the configuration options.
 type Config struct {
-	StackDepth          int    // StackDepth specifies the depth of the function call stack trace. Default value is 10.
+	StackDepth          int    // StackDepth specifies the depth of the function call stack trace, 0 means unlimited. Default value is 10.
 	ErrorConnectionFlag string // ErrorConnectionFlag specifies the error connection flag string. Default value is "\nCaused by: ".
+	PrintFields         bool   // PrintFields specifies whether %+v prints the key/value pairs of each error. Default value is false.
+	PanicCode           int    // PanicCode specifies the code of errors created from recovered panics. Default value is 0.
+	// StackPolicy specifies when a stack trace is recorded. Default value is StackDeepest.
+	StackPolicy StackPolicy
+	// CallerSkip specifies the number of additional stack frames to skip when recording stacks and locations,
+	// so that helpers built on this package can hide their own frames. Default value is 0.
+	CallerSkip int
+	// TrimPaths specifies whether the file paths of stack frames are trimmed of their GOROOT, GOPATH
+	// and module root prefixes. Default value is false.
+	TrimPaths bool
+	// SkipPackages specifies the package path prefixes whose frames are dropped from stacks, e.g. "runtime" or "net/http".
+	// Consecutive dropped frames are collapsed into a single "... N frames elided" line. Default value is nil.
+	SkipPackages []string
+	// JSONOmitStack specifies whether stacks are omitted from the JSON documents of errors. Default value is false.
+	JSONOmitStack bool
+	// LogStack specifies whether the slog values of errors include their stacks, see LogValue. Default value is false.
+	LogStack bool
+	// ExitCodes maps error codes to the exit statuses of Exit and ExitCode. Default value is nil.
+	ExitCodes map[int]int
+	// ExitVerbose specifies whether Exit prints the error with %+v instead of %v,
+	// it can also be enabled with the ERRORS_VERBOSE environment variable. Default value is false.
+	ExitVerbose bool
 }
 
 var (
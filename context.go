@@ -0,0 +1,154 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ContextExtractor returns a value carried by a context, such as a request ID or a trace ID,
+// and whether the context carries it.
+type ContextExtractor func(ctx context.Context) (string, bool)
+
+// contextExtractor is a registered ContextExtractor and the metadata key of its values.
+type contextExtractor struct {
+	key string
+	fn  ContextExtractor
+}
+
+var (
+	extractors   []contextExtractor
+	extractorsRw sync.RWMutex
+)
+
+// RegisterContextExtractor registers fn to copy a value of the contexts passed to NewCtx and WrapCtx
+// into the metadata of errors under key. It is intended to be called from init functions,
+// and panics if key is empty or already registered, or if fn is nil.
+//
+//	func init() {
+//	       errors.RegisterContextExtractor("request_id", func(ctx context.Context) (string, bool) {
+//	               id, ok := ctx.Value(requestIDKey{}).(string)
+//	               return id, ok
+//	       })
+//	}
+func RegisterContextExtractor(key string, fn ContextExtractor) {
+	if key == "" || fn == nil {
+		panic("errors: RegisterContextExtractor called with an empty key or a nil extractor")
+	}
+	extractorsRw.Lock()
+	defer extractorsRw.Unlock()
+	for _, e := range extractors {
+		if e.key == key {
+			panic(fmt.Sprintf("errors: RegisterContextExtractor called twice for key %q", key))
+		}
+	}
+	extractors = append(extractors, contextExtractor{key: key, fn: fn})
+}
+
+// NewCtx creates a new error with a stack trace using the provided message,
+// and the metadata extracted from ctx by the registered extractors, see RegisterContextExtractor.
+func NewCtx(ctx context.Context, msg string) error {
+	return build(nil, 0, &baseError{
+		msg:  msg,
+		meta: extract(ctx),
+	})
+}
+
+// WrapCtx function wraps the incoming error with stack information and message,
+// and the metadata extracted from ctx by the registered extractors.
+// Values already carried by the chain under the same key are not repeated, so that wrapping
+// an error several times with the same context records its metadata once, on the innermost layer,
+// while an error decoded from another process keeps its own metadata next to the local one.
+// If the incoming err is nil, WrapCtx will return nil.
+func WrapCtx(ctx context.Context, e error, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause: e,
+		msg:   msg,
+		meta:  extractNew(ctx, e),
+	})
+}
+
+// Metadata returns the metadata extracted from contexts on the chain of e, merged into a map.
+// The chain is followed through Unwrap and Cause, metadata of outer layers overrides that of inner layers.
+// If the chain carries no metadata, Metadata returns nil.
+func Metadata(e error) map[string]string {
+	var meta map[string]string
+	walk(e, func(err error) bool {
+		if b, ok := err.(*baseError); ok && b != nil {
+			for key, value := range b.meta {
+				if meta == nil {
+					meta = make(map[string]string)
+				}
+				if _, ok := meta[key]; !ok {
+					meta[key] = value
+				}
+			}
+		}
+		return true
+	})
+	return meta
+}
+
+// extractNew runs the registered extractors on ctx and drops the values already carried by the chain of e
+// under the same key. It returns nil if no value is left.
+func extractNew(ctx context.Context, e error) map[string]string {
+	meta := extract(ctx)
+	if len(meta) == 0 {
+		return nil
+	}
+	known := Metadata(e)
+	for key, value := range meta {
+		if v, ok := known[key]; ok && v == value {
+			delete(meta, key)
+		}
+	}
+	if len(meta) == 0 {
+		return nil
+	}
+	return meta
+}
+
+// extract runs the registered extractors on ctx, it returns nil if ctx is nil or carries no value.
+func extract(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	extractorsRw.RLock()
+	defer extractorsRw.RUnlock()
+	var meta map[string]string
+	for _, e := range extractors {
+		value, ok := e.fn(ctx)
+		if !ok {
+			continue
+		}
+		if meta == nil {
+			meta = make(map[string]string)
+		}
+		meta[e.key] = value
+	}
+	return meta
+}
+
+// formatMetadata formats metadata sorted by key, e.g. "{request_id=r-1 tenant=acme}".
+func formatMetadata(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString(fmt.Sprintf("%s=%s", key, meta[key]))
+	}
+	buffer.WriteString("}")
+	return buffer.String()
+}
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+type tenantKey struct{}
+
+func init() {
+	RegisterContextExtractor("request_id", func(ctx context.Context) (string, bool) {
+		id, ok := ctx.Value(requestIDKey{}).(string)
+		return id, ok
+	})
+	RegisterContextExtractor("tenant", func(ctx context.Context) (string, bool) {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		return tenant, ok
+	})
+}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterContextExtractor("request_id", func(ctx context.Context) (string, bool) { return "", false })
+	})
+	assert.Panics(t, func() { RegisterContextExtractor("", nil) })
+}
+
+func TestWrapCtx(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	ctx := context.WithValue(context.WithValue(context.Background(), requestIDKey{}, "r-1"), tenantKey{}, "acme")
+	err := WrapCtx(ctx, WrapCtx(ctx, io.EOF, "read body"), "handle request")
+	assert.Equal(t, map[string]string{"request_id": "r-1", "tenant": "acme"}, Metadata(err))
+	// values already carried by the chain are not repeated
+	assert.Nil(t, err.(*baseError).meta)
+	assert.Equal(t, "handle request: read body: EOF", err.Error())
+	assert.Regexp(t, "^handle request \\(context_test.go:\\d+\\): read body \\(context_test.go:\\d+\\) \\{request_id=r-1 tenant=acme\\}: EOF\n",
+		fmt.Sprintf("%+v", err))
+
+	data, e := json.Marshal(err)
+	assert.NoError(t, e)
+	assert.Contains(t, string(data), `"metadata":{"request_id":"r-1","tenant":"acme"}`)
+	assert.Equal(t, Metadata(err), Metadata(Decode(Encode(err))))
+
+	err = NewCtx(context.WithValue(context.Background(), requestIDKey{}, "r-2"), "no such user")
+	assert.Equal(t, map[string]string{"request_id": "r-2"}, Metadata(fmt.Errorf("wrap: %w", err)))
+
+	assert.Nil(t, Metadata(NewCtx(context.Background(), "no metadata")))
+	assert.Nil(t, Metadata(NewFactory(nil).WrapCtx(nil, io.EOF, "nil context")))
+	assert.Nil(t, WrapCtx(ctx, nil, "nil error"))
+
+	// the metadata of a decoded error does not prevent the local one from being extracted
+	remote := Decode(Encode(NewCtx(context.WithValue(context.Background(), requestIDKey{}, "r-3"), "upstream failed")))
+	err = WrapCtx(ctx, remote, "call upstream")
+	assert.Equal(t, map[string]string{"request_id": "r-1", "tenant": "acme"}, err.(*baseError).meta)
+	assert.Equal(t, map[string]string{"request_id": "r-3"}, Metadata(remote))
+	assert.Equal(t, map[string]string{"request_id": "r-1", "tenant": "acme"}, Metadata(err))
+	err = WrapCtx(context.WithValue(context.Background(), requestIDKey{}, "r-3"), remote, "call upstream")
+	assert.Nil(t, err.(*baseError).meta)
+}
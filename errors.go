@@ -3,8 +3,10 @@ package errors
 
 import (
 	"bytes"
+	stderrors "errors"
 	"fmt"
 	"io"
+	"strings"
 )
 
 // baseError defines an error that includes a stack trace.
@@ -13,6 +15,17 @@ type baseError struct {
 	code  int         // code is the error code
 	msg   string      // msg is the error description
 	stack *StackTrace // stack is the error stack, if the error chain already has a stack, it will not be set again
+	// inline reports whether msg already contains the text of cause, as formatted by a %w verb
+	inline bool
+	fields []Field // fields are the key/value pairs attached to this layer
+	cfg    *Config // cfg is the configuration of the Factory that created the error, nil means the global configuration
+	pc     uintptr // pc is the program counter of the call that created the error, see Location
+	loc    *Frame  // loc is the location of an error decoded by Decode, which has no program counter
+	kind   Kind    // kind is the broad category of the error, see KindOf
+	// retry marks the error as retryable or permanent, nil means unmarked, see IsRetryable
+	retry *retryMark
+	// meta is the metadata extracted from a context.Context, see WrapCtx
+	meta map[string]string
 }
 
 // Error implements the Error interface to print the error chain information.
@@ -27,9 +40,9 @@ func (b *baseError) Error() string {
 		}
 		buffer.WriteString(b.msg)
 	}
-	if b.cause != nil {
+	if b.cause != nil && !b.inline {
 		if buffer.Len() > 0 {
-			buffer.WriteString(GetCfg().ErrorConnectionFlag)
+			buffer.WriteString(b.config().ErrorConnectionFlag)
 		}
 		buffer.WriteString(b.Cause().Error())
 	}
@@ -38,36 +51,12 @@ func (b *baseError) Error() string {
 
 // Format implements the Format interface for printing.
 func (b *baseError) Format(s fmt.State, verb rune) {
-	var stackTrace *StackTrace
-	defer func() {
-		if stackTrace != nil {
-			stackTrace.Format(s, verb)
-		}
-	}()
-
-	var buffer bytes.Buffer
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			if b.code != 0 {
-				buffer.WriteString(fmt.Sprintf("%d", b.code))
-			}
-			if b.msg != "" {
-				if buffer.Len() > 0 {
-					buffer.WriteString(", ")
-				}
-				buffer.WriteString(b.msg)
-			}
-			if b.cause != nil {
-				if buffer.Len() > 0 {
-					buffer.WriteString(GetCfg().ErrorConnectionFlag)
-				}
-				buffer.WriteString(fmt.Sprintf("%+v", b.Cause()))
-			}
+			var buffer bytes.Buffer
+			b.formatVerbose(&buffer, b.stackCount() > 1)
 			_, _ = io.WriteString(s, buffer.String())
-			if b.stack != nil {
-				stackTrace = b.stack
-			}
 			return
 		}
 		fallthrough
@@ -80,24 +69,171 @@ func (b *baseError) Format(s fmt.State, verb rune) {
 	}
 }
 
+// formatVerbose writes the error chain with its stacks into buffer, as printed by %+v.
+// If perLayer is true, each stack is written right after the message of the layer carrying it,
+// otherwise it is written after the whole chain below the layer.
+func (b *baseError) formatVerbose(buffer *bytes.Buffer, perLayer bool) {
+	start := buffer.Len()
+	if b.code != 0 {
+		buffer.WriteString(formatCode(b.code))
+	}
+	if b.msg != "" {
+		if buffer.Len() > start {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(b.msg)
+	}
+	// message layers are followed by their location, so that the chain reads like a return trace
+	if b.hasLocation() && buffer.Len() > start {
+		location := b.Location()
+		buffer.WriteString(fmt.Sprintf(" (%s:%d)", location.RelFile(), location.Line()))
+	}
+	if b.config().PrintFields && len(b.fields) > 0 {
+		if buffer.Len() > start {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString(formatFields(b.fields))
+	}
+	if len(b.meta) > 0 {
+		if buffer.Len() > start {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString(formatMetadata(b.meta))
+	}
+	stacked := buffer.Len()
+	if perLayer {
+		b.formatStacks(buffer)
+	}
+	// an inline cause is already part of the message, only the stacks of its chain are printed
+	if b.cause != nil && b.inline {
+		formatChainStacks(buffer, b.cause)
+	} else if b.cause != nil {
+		if buffer.Len() > start {
+			flag := b.config().ErrorConnectionFlag
+			// a stack ends with a frame, the next layer starts on a new line
+			if buffer.Len() > stacked && !strings.HasPrefix(flag, "\n") {
+				buffer.WriteString("\n")
+			}
+			buffer.WriteString(flag)
+		}
+		if cause, ok := b.cause.(*baseError); ok && cause != nil {
+			cause.formatVerbose(buffer, perLayer)
+		} else if cause, ok := b.cause.(*spawnError); ok {
+			cause.formatVerbose(buffer, perLayer)
+		} else {
+			buffer.WriteString(fmt.Sprintf("%+v", b.cause))
+		}
+	}
+	if !perLayer {
+		b.formatStacks(buffer)
+	}
+}
+
+// formatStacks writes the stack of the layer, if any.
+func (b *baseError) formatStacks(buffer *bytes.Buffer) {
+	if b.stack != nil {
+		buffer.WriteString(fmt.Sprintf("%+v", *b.stack))
+	}
+}
+
+// formatChainStacks writes the stacks carried by the errors of this package on the chain of e, outermost first.
+func formatChainStacks(buffer *bytes.Buffer, e error) {
+	walk(e, func(err error) bool {
+		switch layer := err.(type) {
+		case *baseError:
+			if layer != nil {
+				layer.formatStacks(buffer)
+			}
+		case *spawnError:
+			layer.formatSpawn(buffer)
+		}
+		return true
+	})
+}
+
+// stackCount returns the number of stacks carried by the error chain.
+func (b *baseError) stackCount() int {
+	count := 0
+	walk(b, func(err error) bool {
+		if e, ok := err.(*baseError); ok && e != nil && e.stack != nil {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// config returns the configuration used to print the error.
+// Errors created by a Factory use its configuration, others read the global configuration at print time.
+func (b *baseError) config() *Config {
+	if b.cfg != nil {
+		return b.cfg
+	}
+	return GetCfg()
+}
+
 // StackTrace returns the error chain stack trace.
 // The deepest error created will carry the stack information and shallow errors will not repeat the record.
 func (b *baseError) StackTrace() StackTrace {
 	e := b
 	for e != nil {
 		if e.stack != nil {
+			return *e.stack
+		}
+		var next *baseError
+		if !stderrors.As(e.cause, &next) {
 			break
 		}
-		e, _ = e.Cause().(*baseError)
+		e = next
 	}
-	return *e.stack
+	return StackTrace{}
 }
 
-// Code returns the code.
+// Is reports whether target is an error of this package carrying the same non-zero code,
+// so that a sentinel created with a code matches any error in the chain carrying that code.
+// If target is a Kind, Is reports whether the layer carries that kind.
+func (b *baseError) Is(target error) bool {
+	if k, ok := target.(Kind); ok {
+		return b != nil && k != "" && b.kind == k
+	}
+	t, ok := target.(*baseError)
+	if !ok || t == nil || b == nil {
+		return false
+	}
+	return t.code != 0 && t.code == b.code
+}
+
+// Location returns the frame of the call that created or wrapped the error.
+// Unlike StackTrace, every layer of the chain records its location.
+// It returns the zero Frame if the location is unknown.
+func (b *baseError) Location() Frame {
+	if b.loc != nil {
+		return *b.loc
+	}
+	return frameOf(b.pc, b.config())
+}
+
+// hasLocation reports whether the location of the error is known.
+func (b *baseError) hasLocation() bool {
+	return b.pc != 0 || b.loc != nil
+}
+
+// Code returns the code, it implements the Coder interface.
 func (b *baseError) Code() int {
+	if b == nil {
+		return 0
+	}
 	return b.code
 }
 
+// Kind returns the kind, it implements the Kinder interface.
+func (b *baseError) Kind() Kind {
+	if b == nil {
+		return ""
+	}
+	return b.kind
+}
+
 // Msg returns the message.
 func (b *baseError) Msg() string {
 	return b.msg
@@ -107,4 +243,6 @@ func (b *baseError) Msg() string {
 func (b *baseError) Cause() error { return b.cause }
 
 // Unwrap supports Go 1.13+ error chains.
+// For errors created with multiple %w verbs, which fmt.Errorf supports since Go 1.20,
+// the returned error unwraps to all of them.
 func (b *baseError) Unwrap() error { return b.cause }
@@ -125,3 +125,22 @@ func b2() error {
 func c2() error {
 	return NewWithCode(123, "c2 failed reason")
 }
+
+func TestLocation(t *testing.T) {
+	ResetCfg()
+	err := a()
+	locations := []string{}
+	for e := err; e != nil; e = Unwrap(e) {
+		if b, ok := e.(*baseError); ok {
+			location := b.Location()
+			assert.Equal(t, "errors_test.go", location.RelFile())
+			locations = append(locations, location.Name())
+		}
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, locations)
+	assert.Equal(t, Frame{}, (&baseError{msg: "literal"}).Location())
+
+	s := fmt.Sprintf("%+v", err)
+	assert.Regexp(t, `^a failed reason \(errors_test.go:\d+\)\nCaused by: b failed reason \(errors_test.go:\d+\)\n`+
+		`Caused by: 123, c failed reason \(errors_test.go:\d+\)\nCaused by: open test`, s)
+}
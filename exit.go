@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+const (
+	// VerboseEnv is the environment variable enabling Config.ExitVerbose, e.g. ERRORS_VERBOSE=1.
+	VerboseEnv = "ERRORS_VERBOSE"
+
+	// exitFailure is the exit status of errors without a more specific status.
+	exitFailure = 1
+	// exitTimeout is the exit status of errors caused by context.DeadlineExceeded, like timeout(1).
+	exitTimeout = 124
+	// exitCanceled is the exit status of errors caused by context.Canceled, like a shell interrupted by SIGINT.
+	exitCanceled = 130
+)
+
+var (
+	// osExit, stderr and getenv are replaced in tests.
+	osExit           = os.Exit
+	stderr io.Writer = os.Stderr
+	getenv           = os.Getenv
+)
+
+// ExitCode returns the exit status of a command-line program failing with err:
+//
+//	nil                                 0
+//	code mapped in Config.ExitCodes     the mapped status, for the EffectiveCode of err
+//	*exec.ExitError on the chain        the exit status of the command
+//	context.DeadlineExceeded            124
+//	context.Canceled                    130
+//	any other error                     1
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if status, ok := GetCfg().ExitCodes[EffectiveCode(err)]; ok {
+		return status
+	}
+	var exitErr *exec.ExitError
+	if stderrors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	switch {
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case stderrors.Is(err, context.Canceled):
+		return exitCanceled
+	}
+	return exitFailure
+}
+
+// Exit prints err to stderr and terminates the program with the status returned by ExitCode.
+// The error is printed with %v, or with %+v if Config.ExitVerbose or the ERRORS_VERBOSE environment variable is set.
+// If err is nil, Exit terminates the program with status 0 without printing anything.
+// Deferred functions are not run.
+//
+//	func main() {
+//	       errors.Exit(run())
+//	}
+func Exit(err error) {
+	if err != nil {
+		format := "%v\n"
+		if exitVerbose() {
+			format = "%+v\n"
+		}
+		_, _ = fmt.Fprintf(stderr, format, err)
+	}
+	osExit(ExitCode(err))
+}
+
+// exitVerbose reports whether Exit prints stacks.
+func exitVerbose() bool {
+	if GetCfg().ExitVerbose {
+		return true
+	}
+	verbose, _ := strconv.ParseBool(getenv(VerboseEnv))
+	return verbose
+}
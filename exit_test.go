@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth: 1,
+		ExitCodes:  map[int]int{40401: 3},
+	})
+	defer ResetCfg()
+
+	cmdErr := exec.Command("sh", "-c", "exit 7").Run()
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain", io.EOF, 1},
+		{"mapped code", Wrap(NewWithCode(40401, "no such user"), "load user"), 3},
+		{"unmapped code", NewWithCode(40901, "exists"), 1},
+		{"canceled", Wrap(context.Canceled, "wait"), 130},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), 124},
+		{"command", Wrap(cmdErr, "run hook"), 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestExit(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	var buffer bytes.Buffer
+	status := -1
+	verbose := ""
+	exit, output, env := osExit, stderr, getenv
+	osExit, stderr = func(code int) { status = code }, &buffer
+	getenv = func(key string) string {
+		if key == VerboseEnv {
+			return verbose
+		}
+		return env(key)
+	}
+	defer func() { osExit, stderr, getenv = exit, output, env }()
+
+	Exit(nil)
+	assert.Equal(t, 0, status)
+	assert.Empty(t, buffer.String())
+
+	err := Wrap(io.EOF, "read config")
+	Exit(err)
+	assert.Equal(t, 1, status)
+	assert.Equal(t, "read config: EOF\n", buffer.String())
+
+	buffer.Reset()
+	verbose = "true"
+	Exit(err)
+	assert.Regexp(t, "^read config \\(exit_test.go:\\d+\\): EOF\ngithub.com/morrisxyang/errors.TestExit\n\t.+/exit_test.go:\\d+\n$",
+		buffer.String())
+}
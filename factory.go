@@ -0,0 +1,237 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+)
+
+// Factory creates errors with its own configuration, independent of the global configuration set by SetCfg.
+// Errors remember the Factory that created them, and use its configuration for stack capture and printing.
+//
+//	var errs = errors.NewFactory(&errors.Config{
+//	       StackDepth:          20,
+//	       ErrorConnectionFlag: ": ",
+//	})
+//
+//	func load() error {
+//	       return errs.Wrap(err, "load failed")
+//	}
+type Factory struct {
+	cfg *Config
+}
+
+// NewFactory creates a Factory owning a copy of c.
+// If c is nil, the default configuration is used.
+func NewFactory(c *Config) *Factory {
+	if c == nil {
+		c = defaultCfg
+	}
+	cfg := *c
+	return &Factory{cfg: &cfg}
+}
+
+// New creates an error with a stack trace using the provided message.
+func (f *Factory) New(msg string) error {
+	return build(f.cfg, 0, &baseError{
+		msg: msg,
+	})
+}
+
+// NewSkip creates an error with a stack trace using the provided message, skipping skip additional stack frames.
+// It has the same functionality as the package level NewSkip function.
+func (f *Factory) NewSkip(skip int, msg string) error {
+	return build(f.cfg, skip, &baseError{
+		msg: msg,
+	})
+}
+
+// Errorf formats according to a format specifier and returns the string as a value that satisfies error.
+// It has the same functionality as the package level Errorf function.
+func (f *Factory) Errorf(format string, args ...interface{}) error {
+	return build(f.cfg, 0, newf(format, args...))
+}
+
+// Newf creates a new error with the provided format specifier and arguments.
+// It has the same functionality as the Errorf method.
+func (f *Factory) Newf(format string, args ...interface{}) error {
+	return build(f.cfg, 0, newf(format, args...))
+}
+
+// NewWithCode creates a new error with a stack trace, using the provided code and message.
+func (f *Factory) NewWithCode(code int, msg string) error {
+	return build(f.cfg, 0, &baseError{
+		msg:  msg,
+		code: code,
+	})
+}
+
+// NewWithCodef creates a new error with a stack trace, the provided code, format specifier and arguments.
+// It has the same functionality as the package level NewWithCodef function.
+func (f *Factory) NewWithCodef(code int, format string, args ...interface{}) error {
+	err := newf(format, args...)
+	err.code = code
+	return build(f.cfg, 0, err)
+}
+
+// NewWithFields creates a new error with a stack trace, using the provided message and key/value pairs.
+func (f *Factory) NewWithFields(msg string, kv ...interface{}) error {
+	return build(f.cfg, 0, &baseError{
+		msg:    msg,
+		fields: toFields(kv),
+	})
+}
+
+// Wrap wraps the incoming error with stack information and message.
+// It has the same functionality as the package level Wrap function.
+func (f *Factory) Wrap(e error, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause: e,
+		msg:   msg,
+	})
+}
+
+// WrapSkip wraps the incoming error with stack information and message, skipping skip additional stack frames.
+// It has the same functionality as the package level WrapSkip function.
+func (f *Factory) WrapSkip(skip int, e error, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, skip, &baseError{
+		cause: e,
+		msg:   msg,
+	})
+}
+
+// Wrapf wraps the incoming error with stack information, format specifier and arguments.
+// It has the same functionality as the package level Wrapf function.
+func (f *Factory) Wrapf(e error, format string, args ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause: e,
+		msg:   fmt.Sprintf(format, args...),
+	})
+}
+
+// WrapWithCode wraps the incoming error with stack information, code and message.
+// It has the same functionality as the package level WrapWithCode function.
+func (f *Factory) WrapWithCode(e error, code int, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause: e,
+		msg:   msg,
+		code:  code,
+	})
+}
+
+// WrapWithCodef wraps the incoming error with stack information, code, format specifier and arguments.
+// It has the same functionality as the package level WrapWithCodef function.
+func (f *Factory) WrapWithCodef(e error, code int, format string, args ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause: e,
+		msg:   fmt.Sprintf(format, args...),
+		code:  code,
+	})
+}
+
+// WrapWithPolicy wraps the incoming error with message, recording a stack according to policy.
+// It has the same functionality as the package level WrapWithPolicy function.
+func (f *Factory) WrapWithPolicy(e error, policy StackPolicy, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return buildWithPolicy(f.cfg, 0, policy, &baseError{
+		cause: e,
+		msg:   msg,
+	})
+}
+
+// WithFields wraps the incoming error with key/value pairs and no message.
+// It has the same functionality as the package level WithFields function.
+func (f *Factory) WithFields(e error, kv ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause:  e,
+		fields: toFields(kv),
+	})
+}
+
+// WrapWithFields wraps the incoming error with stack information, message and key/value pairs.
+// It has the same functionality as the package level WrapWithFields function.
+func (f *Factory) WrapWithFields(e error, msg string, kv ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause:  e,
+		msg:    msg,
+		fields: toFields(kv),
+	})
+}
+
+// NewWithKind creates a new error with a stack trace, using the provided kind and message.
+// It has the same functionality as the package level NewWithKind function.
+func (f *Factory) NewWithKind(kind Kind, msg string) error {
+	return build(f.cfg, 0, &baseError{
+		msg:  msg,
+		kind: kind,
+	})
+}
+
+// WrapWithKind wraps the incoming error with stack information, kind and message.
+// It has the same functionality as the package level WrapWithKind function.
+func (f *Factory) WrapWithKind(e error, kind Kind, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause: e,
+		msg:   msg,
+		kind:  kind,
+	})
+}
+
+// WithKind wraps the incoming error with a kind and no message.
+// It has the same functionality as the package level WithKind function.
+func (f *Factory) WithKind(e error, kind Kind) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause: e,
+		kind:  kind,
+	})
+}
+
+// NewCtx creates a new error with a stack trace using the provided message and the metadata extracted from ctx.
+// It has the same functionality as the package level NewCtx function.
+func (f *Factory) NewCtx(ctx context.Context, msg string) error {
+	return build(f.cfg, 0, &baseError{
+		msg:  msg,
+		meta: extract(ctx),
+	})
+}
+
+// WrapCtx wraps the incoming error with stack information, message and the metadata extracted from ctx.
+// It has the same functionality as the package level WrapCtx function.
+func (f *Factory) WrapCtx(ctx context.Context, e error, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return build(f.cfg, 0, &baseError{
+		cause: e,
+		msg:   msg,
+		meta:  extractNew(ctx, e),
+	})
+}
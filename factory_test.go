@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactory(t *testing.T) {
+	ResetCfg()
+	f := NewFactory(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+
+	assert.Nil(t, f.Wrap(nil, "nil"))
+	assert.Nil(t, f.Wrapf(nil, "nil"))
+	assert.Nil(t, f.WrapWithCode(nil, 1, "nil"))
+	assert.Nil(t, f.WrapWithCodef(nil, 1, "nil"))
+	assert.Nil(t, f.WithFields(nil, "k", "v"))
+	assert.Nil(t, f.WrapWithFields(nil, "nil", "k", "v"))
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{f.New("new"), "new"},
+		{f.Newf("new %d", 1), "new 1"},
+		{f.Errorf("read: %w", io.EOF), "read: EOF"},
+		{f.NewWithCode(123, "new"), "123, new"},
+		{f.NewWithCodef(123, "new %d", 1), "123, new 1"},
+		{f.NewWithFields("new", "k", "v"), "new"},
+		{f.Wrap(io.EOF, "read"), "read: EOF"},
+		{f.Wrapf(io.EOF, "read %d", 1), "read 1: EOF"},
+		{f.WrapWithCode(io.EOF, 123, "read"), "123, read: EOF"},
+		{f.WrapWithCodef(io.EOF, 123, "read %d", 1), "123, read 1: EOF"},
+		{f.WithFields(io.EOF, "k", "v"), "EOF"},
+		{f.WrapWithFields(io.EOF, "read", "k", "v"), "read: EOF"},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.want, tt.err.Error(), "test %d", i+1)
+		assert.Regexp(t, "\ngithub.com/morrisxyang/errors.TestFactory\n\t.+/factory_test.go:\\d+$",
+			fmt.Sprintf("%+v", tt.err), "test %d", i+1)
+	}
+}
+
+func TestFactoryIsolation(t *testing.T) {
+	c := &Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: " <- ",
+	}
+	f := NewFactory(c)
+	c.ErrorConnectionFlag = ": "
+
+	SetCfg(&Config{
+		StackDepth:          100,
+		ErrorConnectionFlag: " | ",
+	})
+	defer ResetCfg()
+
+	err := Wrap(f.Wrap(io.EOF, "inner"), "outer")
+	assert.Equal(t, "outer | inner <- EOF", err.Error())
+	assert.Len(t, err.(*baseError).StackTrace().Frames(), 1)
+
+	assert.Equal(t, defaultCfg.ErrorConnectionFlag, NewFactory(nil).cfg.ErrorConnectionFlag)
+}
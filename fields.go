@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// badKey is the key used for values that are not preceded by a string key.
+const badKey = "!BADKEY"
+
+// Field is a key/value pair attached to an error layer.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// WithFields wraps the incoming error with key/value pairs and no message.
+// kv is a list of alternating string keys and values, Field values may also be used directly.
+// If the incoming err already has a stack, the stack will not be set again.
+// If the incoming err is nil, WithFields will return nil.
+func WithFields(e error, kv ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause:  e,
+		fields: toFields(kv),
+	})
+}
+
+// NewWithFields creates a new error with a stack trace, using the provided message and key/value pairs.
+func NewWithFields(msg string, kv ...interface{}) error {
+	return build(nil, 0, &baseError{
+		msg:    msg,
+		fields: toFields(kv),
+	})
+}
+
+// WrapWithFields function wraps the incoming error with stack information, message and key/value pairs.
+// If the incoming err already has a stack, the stack will not be set again.
+// If the incoming err is nil, WrapWithFields will return nil.
+func WrapWithFields(e error, msg string, kv ...interface{}) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause:  e,
+		msg:    msg,
+		fields: toFields(kv),
+	})
+}
+
+// Fields returns the key/value pairs of the whole error chain merged into a map.
+// The chain is followed through Unwrap and Cause, including the branches of errors created by Join.
+// Fields of outer layers override those of inner layers, and those of earlier branches those of later branches.
+// If the chain carries no fields, Fields returns nil.
+func Fields(e error) map[string]interface{} {
+	var layers []*baseError
+	walk(e, func(err error) bool {
+		if layer, ok := err.(*baseError); ok && layer != nil {
+			layers = append(layers, layer)
+		}
+		return true
+	})
+	var fields map[string]interface{}
+	for i := len(layers) - 1; i >= 0; i-- {
+		for _, f := range layers[i].fields {
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			fields[f.Key] = f.Value
+		}
+	}
+	return fields
+}
+
+// toFields converts a list of alternating keys and values into Fields, in the same way as log/slog.
+// A value that is not preceded by a string key gets the key "!BADKEY".
+func toFields(kv []interface{}) []Field {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, (len(kv)+1)/2)
+	for len(kv) > 0 {
+		switch x := kv[0].(type) {
+		case Field:
+			fields = append(fields, x)
+			kv = kv[1:]
+		case string:
+			if len(kv) == 1 {
+				fields = append(fields, Field{Key: badKey, Value: x})
+				kv = kv[1:]
+				continue
+			}
+			fields = append(fields, Field{Key: x, Value: kv[1]})
+			kv = kv[2:]
+		default:
+			fields = append(fields, Field{Key: badKey, Value: x})
+			kv = kv[1:]
+		}
+	}
+	return fields
+}
+
+// formatFields formats fields as "[key=value key=value]", or returns an empty string if there are none.
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+	for i, f := range fields {
+		if i > 0 {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString(fmt.Sprintf("%s=%v", f.Key, f.Value))
+	}
+	buffer.WriteString("]")
+	return buffer.String()
+}
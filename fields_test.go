@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFields(t *testing.T) {
+	assert.Nil(t, WithFields(nil, "k", "v"))
+	assert.Nil(t, WrapWithFields(nil, "msg", "k", "v"))
+
+	err := WithFields(io.EOF, "user_id", 42)
+	assert.Equal(t, io.EOF.Error(), err.Error())
+	assert.Equal(t, io.EOF, Cause(err))
+	assert.NotNil(t, err.(*baseError).stack)
+
+	err = WithFields(NewWithFields("not found", "user_id", 42), "order", "abc")
+	assert.Nil(t, err.(*baseError).stack)
+	assert.Equal(t, "not found", err.Error())
+}
+
+func TestFields(t *testing.T) {
+	assert.Nil(t, Fields(nil))
+	assert.Nil(t, Fields(io.EOF))
+	assert.Nil(t, Fields(New("no fields")))
+
+	inner := NewWithFields("not found", "user_id", 42, "table", "users")
+	err := WrapWithFields(fmt.Errorf("query: %w", inner), "load user", "user_id", 43, Field{Key: "retry", Value: true})
+	assert.Equal(t, map[string]interface{}{
+		"user_id": 43,
+		"table":   "users",
+		"retry":   true,
+	}, Fields(err))
+}
+
+func TestToFields(t *testing.T) {
+	tests := []struct {
+		kv   []interface{}
+		want []Field
+	}{
+		{nil, nil},
+		{[]interface{}{"a", 1, "b", "2"}, []Field{{"a", 1}, {"b", "2"}}},
+		{[]interface{}{Field{"a", 1}, "b", 2}, []Field{{"a", 1}, {"b", 2}}},
+		{[]interface{}{"a"}, []Field{{badKey, "a"}}},
+		{[]interface{}{1, "a", 2}, []Field{{badKey, 1}, {"a", 2}}},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.want, toFields(tt.kv), "test %d", i+1)
+	}
+}
+
+func TestFieldsFormat(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+	err := WithFields(WrapWithFields(io.EOF, "read", "file", "a.txt"), "attempt", 2)
+	assert.Regexp(t, "^read \\(fields_test.go:61\\): EOF\n", fmt.Sprintf("%+v", err))
+
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		PrintFields:         true,
+	})
+	assert.Regexp(t, `^\[attempt=2\]: read \(fields_test.go:61\) \[file=a.txt\]: EOF\n`, fmt.Sprintf("%+v", err))
+	assert.Equal(t, "read: EOF", fmt.Sprintf("%v", err))
+}
+
+// causer is a foreign wrapper that only implements Cause.
+type causer struct{ cause error }
+
+func (c causer) Error() string { return "causer: " + c.cause.Error() }
+func (c causer) Cause() error  { return c.cause }
+
+func TestFieldsChain(t *testing.T) {
+	joined := WithFields(Join(NewWithFields("first", "branch", 1), NewWithFields("second", "branch", 2, "second", true)),
+		"op", "sync")
+	assert.Equal(t, map[string]interface{}{
+		"op":     "sync",
+		"branch": 1,
+		"second": true,
+	}, Fields(joined))
+	assert.Equal(t, map[string]interface{}{"user_id": 42}, Fields(causer{NewWithFields("not found", "user_id", 42)}))
+}
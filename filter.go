@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	prefixesOnce sync.Once
+	prefixes     []string
+)
+
+// resolve converts a runtime frame into a Frame, trimming its file path if cfg.TrimPaths is set.
+func resolve(frame runtime.Frame, cfg *Config) Frame {
+	f := Frame{frame: frame}
+	if cfg.TrimPaths {
+		f.frame.File = trimPath(f)
+	}
+	return f
+}
+
+// skipped reports whether the package of function matches one of the package path prefixes.
+func skipped(function string, packages []string) bool {
+	if len(packages) == 0 {
+		return false
+	}
+	pkg, _ := splitFunction(function)
+	for _, p := range packages {
+		p = strings.TrimSuffix(p, "/")
+		if pkg == p || strings.HasPrefix(pkg, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// trimPath returns the file path of the frame without its GOROOT or GOPATH prefix, or relative to its module root.
+// The path is returned unchanged if none of them applies.
+func trimPath(f Frame) string {
+	file := f.frame.File
+	for _, prefix := range pathPrefixes() {
+		if strings.HasPrefix(file, prefix) {
+			return file[len(prefix):]
+		}
+	}
+	if pkg := f.importPath(); pkg != "" && modulePath(pkg) != "" {
+		return f.RelFile()
+	}
+	return file
+}
+
+// pathPrefixes returns the GOROOT and GOPATH source directories, in slash-separated form as used by runtime frames.
+func pathPrefixes() []string {
+	prefixesOnce.Do(func() {
+		if root := runtime.GOROOT(); root != "" {
+			prefixes = append(prefixes, filepath.ToSlash(filepath.Join(root, "src"))+"/")
+		}
+		gopath := os.Getenv("GOPATH")
+		if gopath == "" {
+			if home, err := os.UserHomeDir(); err == nil {
+				gopath = filepath.Join(home, "go")
+			}
+		}
+		for _, dir := range filepath.SplitList(gopath) {
+			if dir == "" {
+				continue
+			}
+			prefixes = append(prefixes,
+				filepath.ToSlash(filepath.Join(dir, "pkg", "mod"))+"/",
+				filepath.ToSlash(filepath.Join(dir, "src"))+"/")
+		}
+	})
+	return prefixes
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimPaths(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth: 0,
+		TrimPaths:  true,
+	})
+	defer ResetCfg()
+
+	err := New("trim")
+	frames := err.(*baseError).StackTrace().Frames()
+	assert.Equal(t, "filter_test.go", frames[0].File())
+	assert.Equal(t, "filter_test.go", err.(*baseError).Location().File())
+	last := frames[len(frames)-1]
+	assert.Equal(t, "runtime.goexit", last.Function())
+	assert.True(t, strings.HasPrefix(last.File(), "runtime/"), last.File())
+	assert.NotContains(t, fmt.Sprintf("%+v", err), "/usr/")
+}
+
+func TestSkipPackages(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:   0,
+		SkipPackages: []string{"runtime", "testing/"},
+	})
+	defer ResetCfg()
+
+	err := New("skip")
+	frames := err.(*baseError).StackTrace().Frames()
+	assert.Len(t, frames, 2)
+	assert.Equal(t, "github.com/morrisxyang/errors.TestSkipPackages", frames[0].Function())
+	assert.Equal(t, 0, frames[0].Elided())
+	assert.Equal(t, 2, frames[1].Elided())
+	assert.Equal(t, "... 2 frames elided", fmt.Sprintf("%+v", frames[1]))
+
+	assert.Regexp(t, "^skip \\(filter_test.go:\\d+\\)\ngithub.com/morrisxyang/errors.TestSkipPackages\n\t.+/filter_test.go:\\d+\n"+
+		"\\.\\.\\. 2 frames elided$", fmt.Sprintf("%+v", err))
+	assert.Equal(t, "[github.com/morrisxyang/errors.TestSkipPackages ...]", fmt.Sprintf("%v", err.(*baseError).StackTrace()))
+}
+
+func TestSkipped(t *testing.T) {
+	tests := []struct {
+		function string
+		packages []string
+		want     bool
+	}{
+		{"runtime.goexit", nil, false},
+		{"runtime.goexit", []string{"runtime"}, true},
+		{"runtime/debug.Stack", []string{"runtime"}, true},
+		{"net/http.(*conn).serve", []string{"net/http"}, true},
+		{"net/http/httputil.(*ReverseProxy).ServeHTTP", []string{"net/http/"}, true},
+		{"net/httpx.Serve", []string{"net/http"}, false},
+		{"github.com/morrisxyang/errors.New", []string{"runtime", "net/http"}, false},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.want, skipped(tt.function, tt.packages), "test %d", i+1)
+	}
+}
+
+func TestTrimPathsMain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a main package")
+	}
+	goTool := filepath.Join(runtime.GOROOT(), "bin", "go")
+	output, err := exec.Command(goTool, "run", "./testdata/trimmain").CombinedOutput()
+	if err != nil {
+		t.Skipf("cannot run the main package: %v\n%s", err, output)
+	}
+	// frames of the main package are trimmed like its location
+	assert.Regexp(t, "^boom \\(testdata/trimmain/main.go:\\d+\\)\nmain.main\n\ttestdata/trimmain/main.go:\\d+\n$", string(output))
+}
@@ -54,6 +54,30 @@ func TestIs(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "same code",
+			args: args{
+				err:    Wrap(fmt.Errorf("wrap it: %w", NewWithCode(404, "user 1 not found")), "wrap"),
+				target: NewWithCode(404, "not found"),
+			},
+			want: true,
+		},
+		{
+			name: "different code",
+			args: args{
+				err:    WrapWithCode(NewWithCode(500, "internal"), 400, "bad request"),
+				target: NewWithCode(404, "not found"),
+			},
+			want: false,
+		},
+		{
+			name: "without code",
+			args: args{
+				err:    New("test"),
+				target: New("test"),
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,123 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Group runs functions in goroutines and collects the errors they return, the zero value is ready to use.
+// The stack of each Go call is recorded at launch time and attached to the error or recovered panic
+// of the goroutine, so that %+v prints it in a "Spawned by:" section.
+//
+//	var g errors.Group
+//	for _, id := range ids {
+//	       id := id
+//	       g.Go(func() error { return fetch(id) })
+//	}
+//	err := g.Wait()
+type Group struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// Go calls fn in a new goroutine.
+// A panic in fn is recovered and turned into an error in the same way as Recover.
+func (g *Group) Go(fn func() error) {
+	spawn := callers(nil, 0)
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := run(fn); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, &spawnError{
+				err:   err,
+				spawn: spawn,
+			})
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Wait blocks until all goroutines launched by Go have returned, then returns their errors.
+// It returns nil if no goroutine failed, the error itself if exactly one goroutine failed,
+// and the errors joined in the order they were returned otherwise.
+// The collected errors are cleared, so that the Group can be reused for another batch of goroutines.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	errs := g.errs
+	g.errs = nil
+	g.mu.Unlock()
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return Join(errs...)
+}
+
+// run calls fn and recovers from its panic.
+func run(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+// spawnError is the error returned by a goroutine launched by Group.Go, with the stack of the Go call.
+// It is not a layer of its own: Code, Msg, Walk and the JSON document see the error returned by the goroutine,
+// only %+v adds the stack in a "Spawned by:" section.
+type spawnError struct {
+	err   error       // err is the error returned by the goroutine
+	spawn *StackTrace // spawn is the stack of the goroutine that launched the goroutine
+}
+
+// Error returns the message of the error returned by the goroutine.
+func (s *spawnError) Error() string { return s.err.Error() }
+
+// Cause returns the error returned by the goroutine.
+func (s *spawnError) Cause() error { return s.err }
+
+// Unwrap supports Go 1.13+ error chains.
+func (s *spawnError) Unwrap() error { return s.err }
+
+// Format implements the Format interface for printing.
+// %+v prints the error returned by the goroutine followed by the stack of the Go call.
+func (s *spawnError) Format(st fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if st.Flag('+') {
+			var buffer bytes.Buffer
+			perLayer := false
+			if b, ok := s.err.(*baseError); ok && b != nil {
+				perLayer = b.stackCount() > 1
+			}
+			s.formatVerbose(&buffer, perLayer)
+			_, _ = io.WriteString(st, buffer.String())
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(st, s.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(st, "%q", s.Error())
+	default:
+		_, _ = fmt.Fprintf(st, "unsupported format: %%!%c, use %%s: %s", verb, s.Error())
+	}
+}
+
+// formatVerbose writes the error returned by the goroutine and the stack of the Go call into buffer,
+// perLayer is passed on to the errors of this package, see baseError.formatVerbose.
+func (s *spawnError) formatVerbose(buffer *bytes.Buffer, perLayer bool) {
+	if b, ok := s.err.(*baseError); ok && b != nil {
+		b.formatVerbose(buffer, perLayer)
+	} else {
+		buffer.WriteString(fmt.Sprintf("%+v", s.err))
+	}
+	s.formatSpawn(buffer)
+}
+
+// formatSpawn writes the "Spawned by:" section.
+func (s *spawnError) formatSpawn(buffer *bytes.Buffer) {
+	buffer.WriteString("\nSpawned by:")
+	buffer.WriteString(fmt.Sprintf("%+v", *s.spawn))
+}
@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	var g Group
+	assert.Nil(t, g.Wait())
+
+	g.Go(func() error { return nil })
+	assert.Nil(t, g.Wait())
+
+	g.Go(func() error { return WrapWithCode(io.EOF, 123, "read failed") })
+	err := g.Wait()
+	assert.Equal(t, "123, read failed\nCaused by: EOF", err.Error())
+	assert.True(t, Is(err, io.EOF))
+	assert.Equal(t, 123, EffectiveCode(err))
+
+	s := fmt.Sprintf("%+v", err)
+	assert.Regexp(t, "^123, read failed \\(group_test.go:18\\)\nCaused by: EOF\ngithub.com/morrisxyang/errors.TestGroup.func2\n", s)
+	assert.Regexp(t, "\nSpawned by:\ngithub.com/morrisxyang/errors.TestGroup\n\t.+/group_test.go:18\n", s)
+
+	// the errors of the previous batch are not returned again
+	assert.Nil(t, g.Wait())
+
+	// the stack of the Go call is not a layer of its own
+	g.Go(func() error { return NewWithCode(404, "user not found") })
+	err = g.Wait()
+	assert.Equal(t, 404, Code(err))
+	assert.Equal(t, "user not found", Msg(err))
+	assert.Equal(t, 1, Depth(err))
+	assert.Len(t, Layers(err), 1)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "\nSpawned by:\n")
+	assert.Contains(t, string(Encode(err)), `"spawned_by":`)
+	assert.Contains(t, fmt.Sprintf("%+v", Decode(Encode(err))), "\nSpawned by:\n")
+}
+
+func TestGroupPanic(t *testing.T) {
+	var g Group
+	g.Go(func() error { return New("failed") })
+	g.Go(func() error { panic("boom") })
+	err := g.Wait()
+
+	assert.IsType(t, &joinError{}, err)
+	assert.Len(t, err.(*joinError).errs, 2)
+	s := fmt.Sprintf("%+v", err)
+	assert.Contains(t, s, "panic: boom\n\tgithub.com/morrisxyang/errors.TestGroupPanic.func2\n")
+	assert.Regexp(t, "\n\tSpawned by:\n\tgithub.com/morrisxyang/errors.TestGroupPanic\n\t\t.+/group_test.go:46\n", s)
+}
@@ -0,0 +1,211 @@
+// Package httperr renders errors as RFC 7807 problem details (application/problem+json) HTTP responses.
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/morrisxyang/errors"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// Config represents the configuration options.
+type Config struct {
+	// Debug specifies whether responses expose the whole error chain: "detail" holds the message of the chain
+	// and the "debug" member holds the %+v text with stacks. Default value is false.
+	Debug bool
+	// TypeBase specifies the prefix of the "type" member of problems whose code is registered with errors.RegisterCode,
+	// e.g. "https://example.com/problems/" gives "https://example.com/problems/user-not-found".
+	// Default value is "", which gives "about:blank".
+	TypeBase string
+	// StatusTable maps error codes to HTTP statuses, it takes precedence over the statuses registered
+	// with errors.RegisterCode. Default value is nil.
+	StatusTable map[int]int
+}
+
+var (
+	cfg        = defaultCfg
+	defaultCfg = &Config{}
+	rw         sync.RWMutex
+)
+
+// SetCfg sets the global configuration instance.
+func SetCfg(c *Config) {
+	if c == nil {
+		return
+	}
+	rw.Lock()
+	defer rw.Unlock()
+	cfg = c
+}
+
+// GetCfg retrieves the global configuration instance.
+func GetCfg() *Config {
+	rw.RLock()
+	defer rw.RUnlock()
+	return cfg
+}
+
+// ResetCfg resets the global configuration to its default value.
+func ResetCfg() {
+	rw.Lock()
+	defer rw.Unlock()
+	cfg = defaultCfg
+}
+
+// reserved are the members defined by RFC 7807, fields of errors cannot override them.
+var reserved = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+// Problem is a problem details object as defined by RFC 7807.
+type Problem struct {
+	Type     string // Type is a URI reference identifying the problem type
+	Title    string // Title is a short summary of the problem type
+	Status   int    // Status is the HTTP status code
+	Detail   string // Detail is an explanation specific to this occurrence of the problem
+	Instance string // Instance is a URI reference identifying this occurrence of the problem
+	// Extensions are the additional members, e.g. the code and the fields of the error
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON implements the json.Marshaler interface, extension members are written next to the standard ones.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		doc[key] = value
+	}
+	doc["type"] = p.Type
+	doc["title"] = p.Title
+	doc["status"] = p.Status
+	if p.Detail != "" {
+		doc["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		doc["instance"] = p.Instance
+	}
+	return json.Marshal(doc)
+}
+
+// Status translates the EffectiveCode of err into an HTTP status,
+// through Config.StatusTable first and then the statuses registered with errors.RegisterCode.
+// It returns http.StatusOK for nil errors and http.StatusInternalServerError for unknown codes.
+func Status(err error) int {
+	if err != nil {
+		if status, ok := GetCfg().StatusTable[errors.EffectiveCode(err)]; ok {
+			return status
+		}
+	}
+	return errors.HTTPStatus(err)
+}
+
+// NewProblem builds the problem details of err for the request r.
+// The "detail" member holds the first non-empty message of the chain given to a constructor of the errors package,
+// see errors.IsOwn, or the description registered for its code, for client errors (4xx) only.
+// Server errors do not expose their messages unless Config.Debug is set.
+// The code of err and the fields of its chain are added as extension members.
+// NewProblem returns nil if err is nil.
+func NewProblem(r *http.Request, err error) *Problem {
+	if err == nil {
+		return nil
+	}
+	c := GetCfg()
+	status := Status(err)
+	p := &Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Extensions: make(map[string]interface{}),
+	}
+	if r != nil && r.URL != nil {
+		p.Instance = r.URL.RequestURI()
+	}
+	code := errors.EffectiveCode(err)
+	if code != 0 && code != errors.UnknownCode {
+		p.Extensions["code"] = code
+		if desc, ok := errors.CodeInfo(code); ok && desc.Name != "" && c.TypeBase != "" {
+			p.Type = c.TypeBase + strings.Replace(strings.ToLower(desc.Name), "_", "-", -1)
+		}
+	}
+	for key, value := range errors.Fields(err) {
+		if reserved[key] {
+			continue
+		}
+		if e, ok := value.(error); ok {
+			value = e.Error()
+		}
+		p.Extensions[key] = value
+	}
+	switch {
+	case c.Debug:
+		p.Detail = err.Error()
+		p.Extensions["debug"] = fmt.Sprintf("%+v", err)
+	case status < http.StatusInternalServerError:
+		p.Detail = detail(err, code)
+	}
+	return p
+}
+
+// detail returns the first non-empty message of a layer of the chain of err for which errors.IsOwn is true,
+// so that the messages of foreign errors are not exposed, or else the description registered for code.
+func detail(err error, code int) string {
+	var msg string
+	errors.Walk(err, func(layer error, _ int) bool {
+		if errors.IsOwn(layer) && errors.Msg(layer) != "" {
+			msg = errors.Msg(layer)
+			return false
+		}
+		return true
+	})
+	if msg == "" {
+		if desc, ok := errors.CodeInfo(code); ok {
+			msg = desc.Description
+		}
+	}
+	return msg
+}
+
+// WriteProblem writes the problem details of err as an application/problem+json response, see NewProblem.
+// Nothing is written if err is nil.
+//
+//	if err != nil {
+//	       httperr.WriteProblem(w, r, err)
+//	       return
+//	}
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	p := NewProblem(r, err)
+	data, e := json.Marshal(p)
+	if e != nil {
+		// extension members come from error fields, fall back to the standard members
+		p.Extensions = nil
+		data, _ = json.Marshal(p)
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(data)
+}
+
+// HandlerFunc is an HTTP handler returning an error, errors are written by WriteProblem.
+//
+//	http.Handle("/users", httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+//	       user, err := load(r)
+//	       if err != nil {
+//	               return errors.Wrap(err, "load user")
+//	       }
+//	       return json.NewEncoder(w).Encode(user)
+//	}))
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP implements the http.Handler interface.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteProblem(w, r, err)
+	}
+}
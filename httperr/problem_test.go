@@ -0,0 +1,105 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/morrisxyang/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	errors.RegisterCode(40401, "USER_NOT_FOUND", "the user does not exist", http.StatusNotFound, 5)
+	errors.RegisterCode(50301, "DB_UNAVAILABLE", "the database is unavailable", http.StatusServiceUnavailable, 14)
+}
+
+func serve(t *testing.T, err error) (*httptest.ResponseRecorder, map[string]interface{}) {
+	recorder := httptest.NewRecorder()
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error { return err })
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/42?verbose=1", nil))
+	var doc map[string]interface{}
+	if recorder.Body.Len() > 0 {
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+	}
+	return recorder, doc
+}
+
+func TestWriteProblem(t *testing.T) {
+	SetCfg(&Config{TypeBase: "https://example.com/problems/"})
+	defer ResetCfg()
+
+	recorder, doc := serve(t, errors.WrapWithFields(errors.NewWithCode(40401, "no such user"), "user 42 not found",
+		"user_id", 42, "status", "ignored"))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, map[string]interface{}{
+		"type":     "https://example.com/problems/user-not-found",
+		"title":    "Not Found",
+		"status":   float64(404),
+		"detail":   "user 42 not found",
+		"instance": "/users/42?verbose=1",
+		"code":     float64(40401),
+		"user_id":  float64(42),
+	}, doc)
+
+	// server errors do not expose their messages
+	recorder, doc = serve(t, errors.Wrap(io.EOF, "query users"))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, map[string]interface{}{
+		"type":     "about:blank",
+		"title":    "Internal Server Error",
+		"status":   float64(500),
+		"instance": "/users/42?verbose=1",
+	}, doc)
+
+	recorder, doc = serve(t, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Nil(t, doc)
+}
+
+func TestWriteProblemConfig(t *testing.T) {
+	SetCfg(&Config{
+		Debug:       true,
+		StatusTable: map[int]int{50301: http.StatusBadGateway},
+	})
+	defer ResetCfg()
+
+	err := errors.WrapWithCode(io.EOF, 50301, "query users")
+	assert.Equal(t, http.StatusBadGateway, Status(err))
+	assert.Equal(t, http.StatusNotFound, Status(errors.NewWithCode(40401, "no such user")))
+
+	recorder, doc := serve(t, err)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, "about:blank", doc["type"])
+	assert.Equal(t, err.Error(), doc["detail"])
+	assert.Contains(t, doc["debug"], "problem_test.go")
+	assert.Nil(t, NewProblem(nil, nil))
+}
+
+func TestProblemDetail(t *testing.T) {
+	// foreign wrappers do not expose their messages
+	err := fmt.Errorf("select * from users: %w", errors.NewWithCode(40401, "no such user"))
+	assert.Equal(t, "no such user", NewProblem(nil, err).Detail)
+
+	// layers without a message are skipped
+	err = errors.WithFields(errors.NewWithCode(40401, "no such user"), "user_id", 42)
+	assert.Equal(t, "no such user", NewProblem(nil, err).Detail)
+
+	// the registered description is used when no layer has a message
+	err = errors.WrapWithCode(io.EOF, 40401, "")
+	assert.Equal(t, "the user does not exist", NewProblem(nil, err).Detail)
+
+	// foreign errors with a Msg method do not expose it either
+	err = errors.WrapWithCode(msgError{}, 40401, "")
+	assert.Equal(t, "the user does not exist", NewProblem(nil, err).Detail)
+}
+
+// msgError is a foreign error with a Msg method.
+type msgError struct{}
+
+func (msgError) Error() string { return "query failed: password authentication failed" }
+func (msgError) Msg() string   { return "password authentication failed" }
@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// joinError defines an error that aggregates several errors, each branch keeps its own chain and stack.
+type joinError struct {
+	errs []error // errs are the branches of the aggregate error, nil values are discarded
+}
+
+// Join returns an error that wraps the given errors.
+// Any nil error values are discarded, Join returns nil if every value in errs is nil.
+func Join(errs ...error) error {
+	j := &joinError{}
+	for _, err := range errs {
+		if err != nil {
+			j.errs = append(j.errs, err)
+		}
+	}
+	if len(j.errs) == 0 {
+		return nil
+	}
+	return j
+}
+
+// Append appends errs to the branches of err and returns the aggregate error.
+// If err was returned by Join or Append, the returned error contains its branches followed by errs,
+// otherwise err itself becomes the first branch. err is never modified, and nil values are discarded.
+//
+//	var result error
+//	for _, item := range items {
+//	       result = errors.Append(result, process(item))
+//	}
+func Append(err error, errs ...error) error {
+	if j, ok := err.(*joinError); ok && j != nil {
+		return Join(append(append([]error(nil), j.errs...), errs...)...)
+	}
+	return Join(append([]error{err}, errs...)...)
+}
+
+// Error implements the Error interface, the messages of the branches are separated by newlines.
+func (j *joinError) Error() string {
+	var buffer bytes.Buffer
+	for i, err := range j.errs {
+		if i > 0 {
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString(err.Error())
+	}
+	return buffer.String()
+}
+
+// Format implements the Format interface for printing.
+// %+v prints every branch with its stack as an indented tree,
+// each branch is introduced by the ErrorConnectionFlag.
+func (j *joinError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			var buffer bytes.Buffer
+			buffer.WriteString(fmt.Sprintf("%d errors occurred", len(j.errs)))
+			for _, err := range j.errs {
+				buffer.WriteString(GetCfg().ErrorConnectionFlag)
+				buffer.WriteString(strings.Replace(fmt.Sprintf("%+v", err), "\n", "\n\t", -1))
+			}
+			_, _ = io.WriteString(s, buffer.String())
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, j.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", j.Error())
+	default:
+		_, _ = fmt.Fprintf(s, "unsupported format: %%!%c, use %%s: %s", verb, j.Error())
+	}
+}
+
+// Code returns the code of the first branch whose Code is not UnknownCode, that is the code of the outermost
+// error implementing Coder on its chain, which may be 0, e.g. for Join(New("a"), NewWithCode(5, "b")).
+// Branches without any error implementing Coder are skipped, if there is none UnknownCode is returned.
+// Use EffectiveCode to skip the code 0 as well.
+func (j *joinError) Code() int {
+	for _, err := range j.errs {
+		if code := Code(err); code != UnknownCode {
+			return code
+		}
+	}
+	return UnknownCode
+}
+
+// Is reports whether any branch matches target, for Go versions whose errors.Is does not support Unwrap() []error.
+func (j *joinError) Is(target error) bool {
+	for _, err := range j.errs {
+		if Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first branch that matches target, for Go versions whose errors.As does not support Unwrap() []error.
+func (j *joinError) As(target interface{}) bool {
+	for _, err := range j.errs {
+		if As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the branches, it supports Go 1.20+ error trees.
+func (j *joinError) Unwrap() []error { return j.errs }
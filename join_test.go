@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoin(t *testing.T) {
+	if err := Join(); err != nil {
+		t.Errorf("Join(): got %v, want nil", err)
+	}
+	if err := Join(nil, nil); err != nil {
+		t.Errorf("Join(nil, nil): got %v, want nil", err)
+	}
+
+	first := NewWithCode(404, "not found")
+	second := Wrap(io.EOF, "read failed")
+	err := Join(first, nil, second)
+	assert.Equal(t, "404, not found\nread failed\nCaused by: EOF", err.Error())
+	assert.Equal(t, []error{first, second}, err.(interface{ Unwrap() []error }).Unwrap())
+	assert.True(t, Is(err, first))
+	assert.True(t, Is(err, io.EOF))
+	assert.False(t, Is(err, io.ErrUnexpectedEOF))
+
+	var target *baseError
+	assert.True(t, As(err, &target))
+	assert.Equal(t, first, target)
+}
+
+func TestAppend(t *testing.T) {
+	first := New("first")
+	second := New("second")
+	third := New("third")
+
+	assert.Nil(t, Append(nil))
+	assert.Nil(t, Append(nil, nil))
+	assert.Equal(t, first, Append(nil, first).(*joinError).errs[0])
+
+	err := Append(first, second)
+	appended := Append(err, third)
+	assert.Len(t, err.(*joinError).errs, 2, "Append must not modify its argument")
+	assert.Equal(t, []error{first, second, third}, appended.(*joinError).errs)
+}
+
+func TestJoinCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      int
+		effective int
+	}{
+		{"NoCode", Join(errors.New("a"), errors.New("b")), UnknownCode, UnknownCode},
+		{"FirstBranch", Join(NewWithCode(1, "a"), NewWithCode(2, "b")), 1, 1},
+		{"SkipForeign", Join(errors.New("a"), NewWithCode(2, "b")), 2, 2},
+		{"FirstBranchZero", Join(New("a"), NewWithCode(5, "b")), 0, 5},
+		{"Effective", Join(Wrap(NewWithCode(3, "a"), "wrap"), NewWithCode(4, "b")), 0, 3},
+		{"Wrapped", WrapWithCode(Join(errors.New("a"), NewWithCode(5, "b")), 0, "wrap"), 0, 5},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.code, Code(tc.err))
+			assert.Equal(t, tc.effective, EffectiveCode(tc.err))
+		})
+	}
+}
+
+func TestJoinFormat(t *testing.T) {
+	ResetCfg()
+	err := Join(c(), Join(c1(), c2()))
+
+	assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+	assert.Equal(t, err.Error(), fmt.Sprintf("%s", err))
+
+	s := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(s, "2 errors occurred\nCaused by: 123, c failed reason"), s)
+	assert.Contains(t, s, "\n\tCaused by: open test: no such file or directory")
+	assert.Contains(t, s, "\nCaused by: 2 errors occurred\n\tCaused by: 123, c1 failed reason")
+	assert.Contains(t, s, "\n\tgithub.com/morrisxyang/errors.c\n\t\t")
+	assert.Contains(t, s, "\n\t\tgithub.com/morrisxyang/errors.c1\n\t\t\t")
+	assert.Contains(t, s, "\n\t\tgithub.com/morrisxyang/errors.c2\n\t\t\t")
+}
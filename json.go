@@ -0,0 +1,223 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// jsonError is the JSON document of an error layer, its field names are stable.
+type jsonError struct {
+	Code     int                    `json:"code,omitempty"`     // Code is the error code of the layer
+	Name     string                 `json:"name,omitempty"`     // Name is the registered name of the code
+	Kind     string                 `json:"kind,omitempty"`     // Kind is the kind of the layer
+	Message  string                 `json:"message"`            // Message is the message of the layer, or Error() for foreign errors
+	Type     string                 `json:"type,omitempty"`     // Type is the Go type of foreign errors
+	Location *jsonFrame             `json:"location,omitempty"` // Location is the call that created the layer
+	Fields   map[string]interface{} `json:"fields,omitempty"`   // Fields are the key/value pairs of the layer
+	Metadata map[string]string      `json:"metadata,omitempty"` // Metadata is the metadata extracted from a context
+	// Retryable reports whether the layer was marked as retryable or permanent, see IsRetryable
+	Retryable *bool `json:"retryable,omitempty"`
+	// RetryAfterMS is the delay in milliseconds requested before retrying, see RetryAfter
+	RetryAfterMS int64        `json:"retry_after_ms,omitempty"`
+	Stack        []jsonFrame  `json:"stack,omitempty"`      // Stack is the stack carried by the layer
+	SpawnedBy    []jsonFrame  `json:"spawned_by,omitempty"` // SpawnedBy is the stack of the goroutine that launched the layer
+	Cause        *jsonError   `json:"cause,omitempty"`      // Cause is the next layer of the chain
+	Errors       []*jsonError `json:"errors,omitempty"`     // Errors are the branches of joined errors
+	Inline       bool         `json:"inline,omitempty"`     // Inline reports whether Message contains the text of Cause, only set by Encode
+	// Sentinel is the id of the sentinel registered by RegisterSentinel, only set by Encode
+	Sentinel string `json:"sentinel,omitempty"`
+	// Registered is the name of the type registered by RegisterType, only set by Encode
+	Registered string `json:"registered_type,omitempty"`
+	// Data is the JSON encoding of an error of a registered type, only set by Encode
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// jsonFrame is the JSON document of a stack frame.
+type jsonFrame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Elided   int    `json:"elided,omitempty"` // Elided is the number of frames dropped by Config.SkipPackages
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The document contains the code, message, fields, location and stack of every layer, nested through "cause".
+// Stacks are omitted if Config.JSONOmitStack is set.
+func (b *baseError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONError(b, false))
+}
+
+// MarshalJSON implements the json.Marshaler interface, the branches are listed in "errors".
+func (j *joinError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONError(j, false))
+}
+
+// MarshalJSON implements the json.Marshaler interface, the stack of the Go call is listed in "spawned_by".
+func (s *spawnError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONError(s, false))
+}
+
+// ToJSON returns the JSON document of the error chain of e, in the same format as the MarshalJSON method
+// of the errors of this package. Foreign errors are described by their message and Go type,
+// and their chain is followed through Unwrap and Cause. ToJSON returns "null" for a nil error.
+func ToJSON(e error) ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(toJSONError(e, false))
+}
+
+// toJSONError builds the JSON document of e and the errors below it.
+// If wire is true, the document is written for Decode: stacks are included regardless of Config.JSONOmitStack
+// and messages that already contain the text of their cause are marked as inline.
+func toJSONError(e error, wire bool) *jsonError {
+	if e == nil {
+		return nil
+	}
+	doc := toJSONLayer(e, wire)
+	if !wire {
+		return doc
+	}
+	// registered sentinels and types are identified so that Decode can restore them
+	if id, ok := sentinelID(e); ok {
+		doc.Sentinel = id
+	}
+	if name, ok := typeName(e); ok {
+		if data, err := json.Marshal(e); err == nil {
+			doc.Registered = name
+			doc.Data = data
+		}
+	}
+	return doc
+}
+
+// toJSONLayer builds the JSON document of e, the errors below it are built by toJSONError.
+func toJSONLayer(e error, wire bool) *jsonError {
+	switch x := e.(type) {
+	case *baseError:
+		if x == nil {
+			return &jsonError{Message: Success}
+		}
+		return x.toJSONError(wire)
+	case *spawnError:
+		// the stack of the Go call is added to the document of the error returned by the goroutine
+		doc := toJSONError(x.err, wire)
+		if wire || !GetCfg().JSONOmitStack {
+			doc.SpawnedBy = toJSONFrames(x.spawn)
+		}
+		return doc
+	case *joinError:
+		doc := &jsonError{Message: x.Error()}
+		for _, err := range x.errs {
+			doc.Errors = append(doc.Errors, toJSONError(err, wire))
+		}
+		return doc
+	}
+
+	doc := &jsonError{
+		Message: e.Error(),
+		Type:    fmt.Sprintf("%T", e),
+	}
+	// errors rebuilt by Decode keep the type of the original error
+	switch x := e.(type) {
+	case *opaqueError:
+		if x.typ != "" {
+			doc.Type = x.typ
+		}
+	case *opaqueCoder:
+		if x.typ != "" {
+			doc.Type = x.typ
+		}
+	}
+	if c, ok := e.(Coder); ok {
+		doc.Code = c.Code()
+	}
+	switch x := e.(type) {
+	case interface{ Unwrap() error }:
+		doc.Cause = toJSONError(x.Unwrap(), wire)
+	case interface{ Unwrap() []error }:
+		for _, err := range x.Unwrap() {
+			doc.Errors = append(doc.Errors, toJSONError(err, wire))
+		}
+	case interface{ Cause() error }:
+		doc.Cause = toJSONError(x.Cause(), wire)
+	}
+	return doc
+}
+
+// toJSONError builds the JSON document of the layer and the layers below it.
+func (b *baseError) toJSONError(wire bool) *jsonError {
+	doc := &jsonError{
+		Code:    b.code,
+		Kind:    string(b.kind),
+		Message: b.msg,
+	}
+	if desc, ok := CodeInfo(b.code); ok {
+		doc.Name = desc.Name
+	}
+	if b.hasLocation() {
+		location := toJSONFrame(b.Location())
+		doc.Location = &location
+	}
+	if len(b.meta) > 0 {
+		doc.Metadata = b.meta
+	}
+	if b.retry != nil {
+		retryable := b.retry.retryable
+		doc.Retryable = &retryable
+		doc.RetryAfterMS = int64(b.retry.after / time.Millisecond)
+	}
+	for _, f := range b.fields {
+		if doc.Fields == nil {
+			doc.Fields = make(map[string]interface{})
+		}
+		doc.Fields[f.Key] = jsonValue(f.Value)
+	}
+	if wire {
+		doc.Inline = b.inline
+	}
+	if wire || !b.config().JSONOmitStack {
+		doc.Stack = toJSONFrames(b.stack)
+	}
+	if b.cause != nil {
+		doc.Cause = toJSONError(b.cause, wire)
+	}
+	return doc
+}
+
+// toJSONFrames converts the frames of st, it returns nil if st is nil.
+func toJSONFrames(st *StackTrace) []jsonFrame {
+	if st == nil {
+		return nil
+	}
+	var frames []jsonFrame
+	for _, frame := range st.Frames() {
+		frames = append(frames, toJSONFrame(frame))
+	}
+	return frames
+}
+
+// toJSONFrame converts a Frame.
+func toJSONFrame(frame Frame) jsonFrame {
+	if frame.Elided() > 0 {
+		return jsonFrame{Elided: frame.Elided()}
+	}
+	return jsonFrame{
+		Function: frame.Function(),
+		File:     frame.File(),
+		Line:     frame.Line(),
+	}
+}
+
+// jsonValue returns a value of a field that can always be marshalled:
+// errors are replaced by their message and values that cannot be marshalled by their %+v text.
+func jsonValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	err := WrapWithFields(WrapWithCode(io.EOF, 40401, "read user"), "load user", "user_id", 42, "ch", make(chan int))
+	data, e := json.Marshal(err)
+	assert.NoError(t, e)
+
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "load user", doc["message"])
+	assert.Nil(t, doc["code"])
+	assert.Nil(t, doc["stack"])
+	assert.Equal(t, float64(42), doc["fields"].(map[string]interface{})["user_id"])
+	assert.IsType(t, "", doc["fields"].(map[string]interface{})["ch"])
+	assert.Regexp(t, "/json_test.go$", doc["location"].(map[string]interface{})["file"])
+
+	cause := doc["cause"].(map[string]interface{})
+	assert.Equal(t, float64(40401), cause["code"])
+	assert.Equal(t, "USER_NOT_FOUND", cause["name"])
+	assert.Equal(t, "read user", cause["message"])
+	stack := cause["stack"].([]interface{})
+	assert.Len(t, stack, 1)
+	assert.Equal(t, "github.com/morrisxyang/errors.TestMarshalJSON", stack[0].(map[string]interface{})["function"])
+
+	foreign := cause["cause"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"message": "EOF", "type": "*errors.errorString"}, foreign)
+
+	SetCfg(&Config{
+		StackDepth:    1,
+		JSONOmitStack: true,
+	})
+	data, e = json.Marshal(err)
+	assert.NoError(t, e)
+	assert.NotContains(t, string(data), `"stack"`)
+}
+
+func TestToJSON(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:    1,
+		JSONOmitStack: true,
+	})
+	defer ResetCfg()
+
+	data, err := ToJSON(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+
+	data, err = ToJSON(fmt.Errorf("wrap: %w", &baseError{code: 1, msg: "inner"}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"message":"wrap: 1, inner","type":"*fmt.wrapError","cause":{"code":1,"message":"inner"}}`, string(data))
+
+	data, err = ToJSON(Join(codeErr(2), &baseError{msg: "second"}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"message":"code 2\nsecond","errors":[`+
+		`{"code":2,"message":"code 2","type":"errors.codeErr"},{"message":"second"}]}`, string(data))
+}
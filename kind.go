@@ -0,0 +1,86 @@
+package errors
+
+// Kind is a broad category of errors, coarser than codes, used for generic handling such as
+// retrying, choosing between 404 and 500 or alerting. Kind implements error, so that
+// Is(err, KindNotFound) reports whether a layer of the chain of err is of that kind.
+// Applications may define their own kinds, e.g. errors.Kind("payment_required").
+type Kind string
+
+// The built-in kinds.
+const (
+	KindNotFound           Kind = "not_found"
+	KindInvalidArgument    Kind = "invalid_argument"
+	KindPermissionDenied   Kind = "permission_denied"
+	KindUnauthenticated    Kind = "unauthenticated"
+	KindAlreadyExists      Kind = "already_exists"
+	KindConflict           Kind = "conflict"
+	KindFailedPrecondition Kind = "failed_precondition"
+	KindResourceExhausted  Kind = "resource_exhausted"
+	KindUnavailable        Kind = "unavailable"
+	KindTimeout            Kind = "timeout"
+	KindCanceled           Kind = "canceled"
+	KindUnimplemented      Kind = "unimplemented"
+	KindInternal           Kind = "internal"
+)
+
+// Error returns the name of the kind, it implements the error interface.
+func (k Kind) Error() string { return string(k) }
+
+// Kind returns the kind itself, it implements the Kinder interface,
+// so that a Kind wrapped directly, e.g. Wrap(KindNotFound, "no such user"), is found by KindOf.
+func (k Kind) Kind() Kind { return k }
+
+// Kinder is implemented by errors that carry a Kind.
+// Error types outside this package implementing Kinder participate in KindOf.
+type Kinder interface {
+	Kind() Kind
+}
+
+// KindOf returns the first kind found on the chain of e, outermost first, like EffectiveCode.
+// The chain is followed through Unwrap and Cause, honoring every error that implements Kinder.
+// It returns an empty Kind if no layer carries a kind.
+func KindOf(e error) Kind {
+	var kind Kind
+	walk(e, func(err error) bool {
+		if k, ok := err.(Kinder); ok {
+			kind = k.Kind()
+		}
+		return kind == ""
+	})
+	return kind
+}
+
+// NewWithKind creates a new error with a stack trace, using the provided kind and message.
+func NewWithKind(kind Kind, msg string) error {
+	return build(nil, 0, &baseError{
+		msg:  msg,
+		kind: kind,
+	})
+}
+
+// WrapWithKind function wraps the incoming error with stack information, kind and message.
+// If the incoming err already has a stack, the stack will not be set again.
+// If the incoming err is nil, WrapWithKind will return nil.
+func WrapWithKind(e error, kind Kind, msg string) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause: e,
+		msg:   msg,
+		kind:  kind,
+	})
+}
+
+// WithKind wraps the incoming error with a kind and no message.
+// If the incoming err already has a stack, the stack will not be set again.
+// If the incoming err is nil, WithKind will return nil.
+func WithKind(e error, kind Kind) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause: e,
+		kind:  kind,
+	})
+}
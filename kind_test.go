@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type kindErr struct{}
+
+func (kindErr) Error() string { return "throttled" }
+func (kindErr) Kind() Kind    { return KindResourceExhausted }
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"nil", nil, ""},
+		{"no kind", Wrap(io.EOF, "read"), ""},
+		{"new", NewWithKind(KindNotFound, "no such user"), KindNotFound},
+		{"outermost wins", WrapWithKind(NewWithKind(KindNotFound, "no such user"), KindInternal, "load"), KindInternal},
+		{"inner kind", Wrap(WithKind(io.EOF, KindUnavailable), "read"), KindUnavailable},
+		{"foreign wrapper", fmt.Errorf("handle: %w", NewWithKind(KindConflict, "exists")), KindConflict},
+		{"kind wrapped directly", Wrap(KindNotFound, "no such user"), KindNotFound},
+		{"foreign kinder", Wrap(kindErr{}, "call"), KindResourceExhausted},
+		{"join", Join(io.EOF, NewWithKind(KindTimeout, "slow")), KindTimeout},
+		{"factory", NewFactory(nil).WrapWithKind(io.EOF, KindCanceled, "read"), KindCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(tt.err))
+		})
+	}
+}
+
+func TestKindIs(t *testing.T) {
+	err := Wrap(WrapWithKind(io.EOF, KindNotFound, "no such user"), "load user")
+	assert.True(t, Is(err, KindNotFound))
+	assert.False(t, Is(err, KindInternal))
+	assert.True(t, Is(err, io.EOF))
+	assert.Equal(t, "load user\nCaused by: no such user\nCaused by: EOF", err.Error())
+
+	assert.True(t, Is(fmt.Errorf("wrap: %w", KindTimeout), KindTimeout))
+	assert.False(t, Is(New("plain"), Kind("")))
+
+	decoded := Decode(Encode(err))
+	assert.Equal(t, KindNotFound, KindOf(decoded))
+	assert.True(t, Is(decoded, KindNotFound))
+}
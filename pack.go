@@ -1,7 +1,6 @@
 package errors
 
 import (
-	stderrors "errors"
 	"fmt"
 	"math"
 )
@@ -15,48 +14,118 @@ const (
 
 // New creates an error with a stack trace using the provided message
 func New(msg string) error {
-	return &baseError{
-		msg:   msg,
-		stack: callers(),
-	}
+	return build(nil, 0, &baseError{
+		msg: msg,
+	})
+}
+
+// NewSkip creates an error with a stack trace using the provided message, like New,
+// skipping skip additional stack frames so that helpers calling NewSkip can hide their own frames.
+//
+//	func newValidationError(field string) error {
+//	       return errors.NewSkip(1, "invalid "+field)
+//	}
+func NewSkip(skip int, msg string) error {
+	return build(nil, skip, &baseError{
+		msg: msg,
+	})
 }
 
 // Errorf formats according to a format specifier and returns the string
 // as a value that satisfies error.
 // Errorf also records the stack trace at the point it was called.
+// If the format specifier includes %w verbs with error operands, the wrapped errors
+// become the cause of the returned error, in the same way as fmt.Errorf.
+// If a wrapped error already has a stack, the stack will not be set again.
 func Errorf(format string, args ...interface{}) error {
-	return &baseError{
-		msg:   fmt.Sprintf(format, args...),
-		stack: callers(),
-	}
+	return build(nil, 0, newf(format, args...))
 }
 
 // Newf creates a new error with the provided format specifier and arguments.
-// It has the same functionality as New function
+// It has the same functionality as Errorf function
 func Newf(format string, args ...interface{}) error {
-	return &baseError{
-		msg:   fmt.Sprintf(format, args...),
-		stack: callers(),
-	}
+	return build(nil, 0, newf(format, args...))
 }
 
 // NewWithCode creates a new error with a stack trace, using the provided code and message.
 func NewWithCode(code int, msg string) error {
-	return &baseError{
-		msg:   msg,
-		stack: callers(),
-		code:  code,
-	}
+	return build(nil, 0, &baseError{
+		msg:  msg,
+		code: code,
+	})
 }
 
 // NewWithCodef creates a new error with a stack trace, the provided code, format specifier and arguments.
-// This function has the same functionality as the NewWithCode function.
+// This function has the same functionality as the NewWithCode function,
+// and supports %w verbs in the same way as the Errorf function.
 func NewWithCodef(code int, format string, args ...interface{}) error {
-	return &baseError{
-		msg:   fmt.Sprintf(format, args...),
-		stack: callers(),
-		code:  code,
+	err := newf(format, args...)
+	err.code = code
+	return build(nil, 0, err)
+}
+
+// newf formats the message with fmt.Errorf and records the errors wrapped by %w verbs as the cause.
+// A single wrapped error becomes the cause itself, multiple wrapped errors, only supported by fmt.Errorf
+// since Go 1.20, are joined together.
+func newf(format string, args ...interface{}) *baseError {
+	formatted := fmt.Errorf(format, args...)
+	err := &baseError{
+		msg: formatted.Error(),
+	}
+	switch x := formatted.(type) {
+	case interface{ Unwrap() error }:
+		err.cause = x.Unwrap()
+	case interface{ Unwrap() []error }:
+		err.cause = Join(x.Unwrap()...)
+	}
+	// the message already contains the text of the wrapped errors
+	err.inline = err.cause != nil
+	return err
+}
+
+// build sets the configuration of err, records the location of the caller of the exported function and the stack trace starting there,
+// skip is the number of additional stack frames to skip. A nil cfg means the global configuration.
+// Whether the stack is recorded follows the StackPolicy of the configuration.
+func build(cfg *Config, skip int, err *baseError) *baseError {
+	policy := GetCfg().StackPolicy
+	if cfg != nil {
+		policy = cfg.StackPolicy
+	}
+	return buildWithPolicy(cfg, skip+1, policy, err)
+}
+
+// buildWithPolicy is build with an explicit StackPolicy.
+func buildWithPolicy(cfg *Config, skip int, policy StackPolicy, err *baseError) *baseError {
+	err.cfg = cfg
+	err.pc = caller(cfg, skip+1)
+	if needStack(policy, err.cause) {
+		err.stack = callers(cfg, skip+1)
 	}
+	return err
+}
+
+// needStack reports whether an error wrapping cause records a stack under the policy.
+func needStack(policy StackPolicy, cause error) bool {
+	switch policy {
+	case StackEveryWrap:
+		return true
+	case StackAfterForeign:
+		_, ok := cause.(*baseError)
+		return !ok
+	case StackNever:
+		return false
+	default:
+		// If no error on the chain carries a stack, it is the first time to package and add stack information
+		return !hasStack(cause)
+	}
+}
+
+// hasStack reports whether an error of this package on the chain of e carries a stack.
+func hasStack(e error) bool {
+	return walk(e, func(err error) bool {
+		layer, ok := err.(*baseError)
+		return !ok || layer == nil || layer.stack == nil
+	})
 }
 
 // Wrap function wraps the incoming error with stack information and message.
@@ -67,16 +136,23 @@ func Wrap(e error, msg string) error {
 	if e == nil {
 		return nil
 	}
-	wrapErr := &baseError{
+	return build(nil, 0, &baseError{
 		cause: e,
 		msg:   msg,
+	})
+}
+
+// WrapSkip function wraps the incoming error with stack information and message, like Wrap,
+// skipping skip additional stack frames so that helpers calling WrapSkip can hide their own frames.
+// If the incoming err is nil, WrapSkip will return nil.
+func WrapSkip(skip int, e error, msg string) error {
+	if e == nil {
+		return nil
 	}
-	var fd *baseError
-	if !stderrors.As(e, &fd) {
-		// If there is no error of the same type on the link, it means that it is the first time to package and add stack information
-		wrapErr.stack = callers()
-	}
-	return wrapErr
+	return build(nil, skip, &baseError{
+		cause: e,
+		msg:   msg,
+	})
 }
 
 // Wrapf function wraps the incoming error with stack information, format specifier and arguments.
@@ -85,16 +161,10 @@ func Wrapf(e error, format string, args ...interface{}) error {
 	if e == nil {
 		return nil
 	}
-	wrapErr := &baseError{
+	return build(nil, 0, &baseError{
 		cause: e,
 		msg:   fmt.Sprintf(format, args...),
-	}
-	var fd *baseError
-	if !stderrors.As(e, &fd) {
-		// If there is no error of the same type on the link, it means that it is the first time to package and add stack information
-		wrapErr.stack = callers()
-	}
-	return wrapErr
+	})
 }
 
 // WrapWithCode function wraps the incoming error with stack information, code and message.
@@ -104,17 +174,11 @@ func WrapWithCode(e error, code int, msg string) error {
 	if e == nil {
 		return nil
 	}
-	wrapErr := &baseError{
+	return build(nil, 0, &baseError{
 		cause: e,
 		msg:   msg,
 		code:  code,
-	}
-	var fd *baseError
-	if !stderrors.As(e, &fd) {
-		// If there is no error of the same type on the link, it means that it is the first time to package and add stack information
-		wrapErr.stack = callers()
-	}
-	return wrapErr
+	})
 }
 
 // WrapWithCodef function wraps the incoming error with stack information, code, format specifier and arguments.
@@ -123,33 +187,63 @@ func WrapWithCodef(e error, code int, format string, args ...interface{}) error
 	if e == nil {
 		return nil
 	}
-	wrapErr := &baseError{
+	return build(nil, 0, &baseError{
 		cause: e,
 		msg:   fmt.Sprintf(format, args...),
 		code:  code,
+	})
+}
+
+// WrapWithPolicy function wraps the incoming error with message, recording a stack according to policy
+// instead of the StackPolicy of the configuration.
+// If the incoming err is nil, WrapWithPolicy will return nil.
+func WrapWithPolicy(e error, policy StackPolicy, msg string) error {
+	if e == nil {
+		return nil
 	}
-	var fd *baseError
-	if !stderrors.As(e, &fd) {
-		// If there is no error of the same type on the link, it means that it is the first time to package and add stack information
-		wrapErr.stack = callers()
-	}
-	return wrapErr
+	return buildWithPolicy(nil, 0, policy, &baseError{
+		cause: e,
+		msg:   msg,
+	})
 }
 
-// Code function returns the error code associated with an error object if it is of type *baseError.
-// If the error object is not of type *baseError, it returns the minimum value of int32.
+// Coder is implemented by errors that carry an error code.
+// Error types outside this package implementing Coder participate in Code, EffectiveCode and IsCode.
+type Coder interface {
+	Code() int
+}
+
+// Code function returns the error code of the outermost error in the chain that implements Coder.
+// The chain is followed through Unwrap and Cause, so foreign wrappers such as fmt.Errorf("%w") are seen through.
+// For errors created by Join, it returns the code of the first branch whose chain contains an error implementing Coder,
+// which is the outermost code of that branch and may be 0.
+// If no error in the chain implements Coder, it returns the minimum value of int32.
 func Code(e error) int {
 	if e == nil {
 		return 0
 	}
-	err, ok := e.(*baseError)
-	if !ok {
-		return UnknownCode
-	}
-	if err == (*baseError)(nil) {
-		return 0
+	code := UnknownCode
+	walk(e, func(err error) bool {
+		if c, ok := err.(Coder); ok {
+			code = c.Code()
+			return false
+		}
+		return true
+	})
+	return code
+}
+
+// IsCode reports whether any error in the chain of e carries the code.
+// Unlike Code, the whole chain is searched, including foreign wrappers such as fmt.Errorf("%w")
+// and the branches of errors created by Join. IsCode always returns false for code 0.
+func IsCode(e error, code int) bool {
+	if code == 0 {
+		return false
 	}
-	return err.Code()
+	return walk(e, func(err error) bool {
+		c, ok := err.(Coder)
+		return !ok || c.Code() != code
+	})
 }
 
 // Msg function returns the error message associated with an error object if it is of type *baseError.
@@ -158,6 +252,9 @@ func Msg(e error) string {
 	if e == nil {
 		return ""
 	}
+	if s, ok := e.(*spawnError); ok {
+		return Msg(s.err)
+	}
 	err, ok := e.(*baseError)
 	if !ok {
 		return e.Error()
@@ -168,6 +265,17 @@ func Msg(e error) string {
 	return err.Msg()
 }
 
+// IsOwn reports whether e itself is an error created by New, Wrap or another constructor of this package,
+// or rebuilt by Decode, so that Msg returns the message given when it was created.
+// Only e is checked, not its chain. IsOwn returns false for foreign errors and for errors created by Join.
+func IsOwn(e error) bool {
+	if s, ok := e.(*spawnError); ok {
+		e = s.err
+	}
+	b, ok := e.(*baseError)
+	return ok && b != nil
+}
+
 // Cause returns the underlying cause of the error, if possible.
 // An error value has a cause if it implements the following
 // interface:
@@ -201,39 +309,30 @@ func Cause(e error) error {
 }
 
 // EffectiveCode returns the first valid error code from the error chain.
-// If error object encountered is not of type *baseError, it will return UnknownCode.
+// The chain is followed through Unwrap and Cause, honoring every error that implements Coder.
+// Errors created by Join are searched branch by branch, in the order they were joined.
+// If no valid error code is found, it will return UnknownCode.
 func EffectiveCode(e error) int {
 	if e == nil {
 		return 0
 	}
-	type (
-		causer interface {
-			Cause() error
-		}
-	)
-	for {
-		err, ok := e.(*baseError)
-		if !ok {
-			break
-		}
+	code := UnknownCode
+	walk(e, func(err error) bool {
 		if err == (*baseError)(nil) {
-			return 0
+			code = 0
+			return false
 		}
-		// effective
-		if err.Code() != 0 {
-			return err.Code()
-		}
-		// through chain
-		cause, ok := e.(causer)
+		c, ok := err.(Coder)
 		if !ok {
-			break
+			return true
 		}
-		if cause.Cause() != nil {
-			e = cause.Cause()
-			continue
+		// effective
+		if c.Code() != 0 && c.Code() != UnknownCode {
+			code = c.Code()
+			return false
 		}
-		break
-	}
+		return true
+	})
 	// If no effective code was found, return UnknownCode.
-	return UnknownCode
+	return code
 }
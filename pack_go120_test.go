@@ -0,0 +1,30 @@
+//go:build go1.20
+// +build go1.20
+
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorfWrapMultiple(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          0,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	foreign := errors.New("EOF")
+	other := errors.New("timeout")
+	err := NewWithCodef(500, "both: %w, %w", foreign, other)
+	if got, want := err.Error(), "500, both: EOF, timeout"; got != want {
+		t.Errorf("NewWithCodef(%%w, %%w).Error(): got %q, want %q", got, want)
+	}
+	if !Is(err, foreign) || !Is(err, other) {
+		t.Errorf("Is(NewWithCodef(%%w, %%w), ...): want both wrapped errors to match")
+	}
+	if got := err.(*baseError).StackTrace(); len(got.Frames()) == 0 {
+		t.Errorf("NewWithCodef(%%w, %%w).StackTrace() should return a stack")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNew(t *testing.T) {
@@ -331,6 +333,10 @@ func TestEffectiveCode(t *testing.T) {
 		{name: "ChainWithMultiCode",
 			err: &baseError{code: 0, cause: &baseError{code: 123, cause: &baseError{code: 456}}}, expected: 123},
 		{name: "ChainWithUnknown", err: &baseError{cause: errors.New("unknown error")}, expected: UnknownCode},
+		{name: "ForeignWrapper", err: fmt.Errorf("wrap: %w", &baseError{cause: &baseError{code: 456}}), expected: 456},
+		{name: "Coder", err: &baseError{cause: fmt.Errorf("wrap: %w", codeErr(789))}, expected: 789},
+		{name: "Joined", err: Join(errors.New("unknown error"), &baseError{code: 0, cause: codeErr(321)}),
+			expected: 321},
 	}
 
 	// Run each test case
@@ -343,3 +349,215 @@ func TestEffectiveCode(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorfWrap(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          0,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	inner := NewWithCode(404, "user not found")
+	err := Errorf("load user: %w", inner)
+	if got, want := err.Error(), "load user: 404, user not found"; got != want {
+		t.Errorf("Errorf(%%w).Error(): got %q, want %q", got, want)
+	}
+	if got := Unwrap(err); got != inner {
+		t.Errorf("Unwrap(Errorf(%%w)): got %#v, want %#v", got, inner)
+	}
+	if !Is(err, inner) {
+		t.Errorf("Is(Errorf(%%w), inner): got false, want true")
+	}
+	if got := EffectiveCode(err); got != 404 {
+		t.Errorf("EffectiveCode(Errorf(%%w)): got %d, want %d", got, 404)
+	}
+	if err.(*baseError).stack != nil {
+		t.Errorf("Errorf(%%w) should not record a stack when the wrapped error has one")
+	}
+	if got := err.(*baseError).StackTrace(); len(got.Frames()) == 0 {
+		t.Errorf("Errorf(%%w).StackTrace() should return the stack of the wrapped error")
+	}
+
+	foreign := errors.New("EOF")
+	err = Newf("read: %w", foreign)
+	if err.(*baseError).stack == nil {
+		t.Errorf("Newf(%%w) should record a stack when the wrapped error has none")
+	}
+	if Cause(err) != foreign {
+		t.Errorf("Cause(Newf(%%w)): got %#v, want %#v", Cause(err), foreign)
+	}
+}
+
+func TestIsCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+		want bool
+	}{
+		{name: "Nil", err: nil, code: 404, want: false},
+		{name: "Foreign", err: errors.New("unknown error"), code: 404, want: false},
+		{name: "ZeroCode", err: New("no code"), code: 0, want: false},
+		{name: "Outermost", err: NewWithCode(404, "not found"), code: 404, want: true},
+		{name: "Inner", err: WrapWithCode(NewWithCode(404, "not found"), 500, "internal"), code: 404, want: true},
+		{name: "ForeignWrapper", err: Wrap(fmt.Errorf("wrap: %w", NewWithCode(404, "not found")), "wrap"),
+			code: 404, want: true},
+		{name: "Joined", err: Join(io.EOF, NewWithCode(404, "not found")), code: 404, want: true},
+		{name: "Missing", err: WrapWithCode(NewWithCode(400, "bad"), 500, "internal"), code: 404, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsCode(tc.err, tc.code); got != tc.want {
+				t.Errorf("IsCode(%v, %d): got %v, want %v", tc.err, tc.code, got, tc.want)
+			}
+		})
+	}
+}
+
+type codeErr int
+
+func (c codeErr) Error() string { return fmt.Sprintf("code %d", int(c)) }
+
+func (c codeErr) Code() int { return int(c) }
+
+func TestCoder(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "Coder", err: codeErr(123), want: 123},
+		{name: "ForeignWrapper", err: fmt.Errorf("wrap: %w", codeErr(123)), want: 123},
+		{name: "OutermostCoder", err: fmt.Errorf("wrap: %w", Wrap(codeErr(123), "wrap")), want: 0},
+		{name: "PkgCoder", err: fmt.Errorf("wrap: %w", NewWithCode(456, "with code")), want: 456},
+		{name: "NoCoder", err: fmt.Errorf("wrap: %w", io.EOF), want: UnknownCode},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Code(tc.err); got != tc.want {
+				t.Errorf("Code(%v): got %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+	if !IsCode(Wrap(codeErr(123), "wrap"), 123) {
+		t.Errorf("IsCode should honor errors implementing Coder")
+	}
+}
+
+func TestErrorfFormat(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	assert.Regexp(t, "^read: EOF \\(pack_test.go:\\d+\\)\ngithub.com/morrisxyang/errors.TestErrorfFormat\n\t.+/pack_test.go:\\d+$",
+		fmt.Sprintf("%+v", Errorf("read: %w", io.EOF)))
+	assert.Regexp(t, "^load: 404, not found \\(pack_test.go:\\d+\\)\ngithub.com/morrisxyang/errors.TestErrorfFormat\n\t.+/pack_test.go:\\d+$",
+		fmt.Sprintf("%+v", Errorf("load: %w", NewWithCode(404, "not found"))))
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		StackPolicy:         StackEveryWrap,
+	})
+	assert.Regexp(t, "^outer \\(pack_test.go:\\d+\\)\n"+
+		"github.com/morrisxyang/errors.TestErrorfFormat\n\t.+/pack_test.go:\\d+\n: load: 404, not found \\(pack_test.go:\\d+\\)\n"+
+		"github.com/morrisxyang/errors.TestErrorfFormat\n\t.+/pack_test.go:\\d+\n"+
+		"github.com/morrisxyang/errors.TestErrorfFormat\n\t.+/pack_test.go:\\d+$",
+		fmt.Sprintf("%+v", Wrap(Errorf("load: %w", NewWithCode(404, "not found")), "outer")))
+}
+
+func TestStackPolicy(t *testing.T) {
+	defer ResetCfg()
+	stacks := func(err error) int { return err.(*baseError).stackCount() }
+	crossed := func() error { return fmt.Errorf("queue: %w", New("inner")) }
+
+	tests := []struct {
+		policy  StackPolicy
+		new     int
+		wrap    int
+		foreign int
+	}{
+		{StackDeepest, 1, 1, 1},
+		{StackEveryWrap, 1, 2, 2},
+		{StackAfterForeign, 1, 1, 2},
+		{StackNever, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		SetCfg(&Config{
+			StackDepth:          1,
+			ErrorConnectionFlag: ": ",
+			StackPolicy:         tt.policy,
+		})
+		assert.Equal(t, tt.new, stacks(New("new")), "policy %d: New", tt.policy)
+		assert.Equal(t, tt.wrap, stacks(Wrap(New("inner"), "outer")), "policy %d: Wrap", tt.policy)
+		assert.Equal(t, tt.foreign, stacks(Wrap(crossed(), "outer")), "policy %d: Wrap after foreign", tt.policy)
+	}
+
+	ResetCfg()
+	assert.Equal(t, 2, stacks(WrapWithPolicy(New("inner"), StackEveryWrap, "outer")))
+	assert.Equal(t, 0, stacks(WrapWithPolicy(io.EOF, StackNever, "outer")))
+	assert.Nil(t, WrapWithPolicy(nil, StackEveryWrap, "outer"))
+	f := NewFactory(&Config{StackPolicy: StackNever})
+	assert.Equal(t, 0, stacks(f.Wrap(io.EOF, "outer")))
+	assert.Equal(t, 1, stacks(f.WrapWithPolicy(io.EOF, StackDeepest, "outer")))
+
+	// errors of this package without a stack do not prevent the stack from being recorded
+	assert.Equal(t, 1, stacks(Wrap(f.New("inner"), "outer")))
+	assert.Equal(t, 1, stacks(Wrap(Decode(Encode(f.New("inner"))), "outer")))
+	var g Group
+	g.Go(func() error { return io.EOF })
+	assert.Equal(t, 1, stacks(Wrap(g.Wait(), "outer")))
+
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		StackPolicy:         StackNever,
+	})
+	err := Errorf("load: %w", NewWithCode(404, "not found"))
+	assert.Regexp(t, `^load: 404, not found \(pack_test.go:\d+\)$`, fmt.Sprintf("%+v", err))
+}
+
+func TestStackPerLayerFormat(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: "\nCaused by: ",
+		StackPolicy:         StackEveryWrap,
+	})
+	defer ResetCfg()
+
+	err := Wrap(Wrap(io.EOF, "inner"), "outer")
+	assert.Regexp(t, "^outer \\(pack_test.go:\\d+\\)\ngithub.com/morrisxyang/errors.TestStackPerLayerFormat\n\t.+/pack_test.go:\\d+\n"+
+		"Caused by: inner \\(pack_test.go:\\d+\\)\ngithub.com/morrisxyang/errors.TestStackPerLayerFormat\n\t.+/pack_test.go:\\d+\n"+
+		"Caused by: EOF$", fmt.Sprintf("%+v", err))
+
+	// the next layer does not continue the line of the last frame
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		StackPolicy:         StackEveryWrap,
+	})
+	err = Wrap(Wrap(io.EOF, "inner"), "outer")
+	assert.Regexp(t, "^outer \\(pack_test.go:\\d+\\)\ngithub.com/morrisxyang/errors.TestStackPerLayerFormat\n\t.+/pack_test.go:\\d+\n"+
+		": inner \\(pack_test.go:\\d+\\)\ngithub.com/morrisxyang/errors.TestStackPerLayerFormat\n\t.+/pack_test.go:\\d+\n"+
+		": EOF$", fmt.Sprintf("%+v", err))
+
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: "\nCaused by: ",
+	})
+	err = Wrap(Wrap(io.EOF, "inner"), "outer")
+	assert.Regexp(t, "^outer \\(pack_test.go:\\d+\\)\nCaused by: inner \\(pack_test.go:\\d+\\)\nCaused by: EOF\ngithub.com/morrisxyang/errors.TestStackPerLayerFormat\n",
+		fmt.Sprintf("%+v", err))
+}
+
+func TestIsOwn(t *testing.T) {
+	assert.False(t, IsOwn(nil))
+	assert.False(t, IsOwn(io.EOF))
+	assert.False(t, IsOwn((*baseError)(nil)))
+	assert.False(t, IsOwn(fmt.Errorf("wrap: %w", New("inner"))))
+	assert.False(t, IsOwn(Join(New("a"), New("b"))))
+	assert.True(t, IsOwn(New("new")))
+	assert.True(t, IsOwn(Wrap(io.EOF, "wrap")))
+	assert.True(t, IsOwn(Decode(Encode(New("remote")))))
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// Recover recovers from a panic of the current goroutine and stores it in *errp
+// as an error whose stack starts at the panic site. It must be deferred directly:
+//
+//	func handle() (err error) {
+//	       defer errors.Recover(&err)
+//	       ...
+//	}
+//
+// The error carries Config.PanicCode, a panic value of type error becomes its cause.
+// If errp is nil, the panic is recovered and discarded.
+func Recover(errp *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	err := fromPanic(r)
+	if errp != nil {
+		*errp = err
+	}
+}
+
+// RecoverValue converts a value returned by recover into an error whose stack starts at the panic site.
+// It is intended for deferred functions that need to handle the panic value themselves:
+//
+//	defer func() {
+//	       if err := errors.RecoverValue(recover()); err != nil {
+//	               log.Printf("%+v", err)
+//	       }
+//	}()
+//
+// The error carries Config.PanicCode, a panic value of type error becomes its cause.
+// If v is nil, RecoverValue returns nil.
+func RecoverValue(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	return fromPanic(v)
+}
+
+// fromPanic creates an error from a panic value, with the stack of the panic site.
+func fromPanic(v interface{}) *baseError {
+	err := &baseError{
+		code:  GetCfg().PanicCode,
+		stack: panicCallers(),
+	}
+	if cause, ok := v.(error); ok {
+		err.msg = "panic"
+		err.cause = cause
+	} else {
+		err.msg = fmt.Sprintf("panic: %v", v)
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func panicValue() (err error) {
+	defer Recover(&err)
+	panic("boom")
+}
+
+func panicError() (err error) {
+	defer Recover(&err)
+	panic(WrapWithCode(io.EOF, 123, "read failed"))
+}
+
+func panicRuntime() (err error) {
+	defer Recover(&err)
+	var m map[string]int
+	m["key"] = 1
+	return nil
+}
+
+func panicHandled() (err error) {
+	defer func() {
+		err = RecoverValue(recover())
+	}()
+	panic("handled")
+}
+
+func TestRecover(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		PanicCode:           500,
+	})
+	defer ResetCfg()
+
+	err := panicValue()
+	assert.Equal(t, "500, panic: boom", err.Error())
+	assert.Equal(t, 500, Code(err))
+	assert.Regexp(t, "^500, panic: boom\ngithub.com/morrisxyang/errors.panicValue\n\t.+/recover_test.go:13$",
+		fmt.Sprintf("%+v", err))
+
+	err = panicError()
+	assert.Equal(t, "500, panic: 123, read failed: EOF", err.Error())
+	assert.True(t, Is(err, io.EOF))
+	assert.Equal(t, 500, EffectiveCode(err))
+	assert.Regexp(t, "^500, panic\ngithub.com/morrisxyang/errors.panicError\n\t.+/recover_test.go:18\n: 123, read failed \\(recover_test.go:18\\)\n",
+		fmt.Sprintf("%+v", err))
+
+	err = panicRuntime()
+	assert.Contains(t, err.Error(), "assignment to entry in nil map")
+	assert.Regexp(t, "\ngithub.com/morrisxyang/errors.panicRuntime\n\t.+/recover_test.go:24$", fmt.Sprintf("%+v", err))
+
+	err = panicHandled()
+	assert.Regexp(t, "^500, panic: handled\ngithub.com/morrisxyang/errors.panicHandled\n\t.+/recover_test.go:32$",
+		fmt.Sprintf("%+v", err))
+}
+
+func TestRecoverValue(t *testing.T) {
+	assert.Nil(t, RecoverValue(nil))
+	assert.NotPanics(t, func() {
+		defer Recover(nil)
+		panic("discarded")
+	})
+
+	err := RecoverValue("not panicking")
+	frames := err.(*baseError).StackTrace().Frames()
+	assert.Equal(t, "github.com/morrisxyang/errors.TestRecoverValue", frames[0].Function())
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const (
+	// grpcOK is the gRPC status code returned for nil errors, codes.OK.
+	grpcOK = 0
+	// grpcUnknown is the gRPC status code returned for unregistered codes, codes.Unknown.
+	grpcUnknown = 2
+)
+
+// CodeDesc describes a registered error code.
+type CodeDesc struct {
+	Code        int    // Code is the error code carried by errors
+	Name        string // Name is the symbolic name of the code, e.g. "USER_NOT_FOUND"
+	Description string // Description is a human-readable description of the code
+	HTTPStatus  int    // HTTPStatus is the HTTP status the code is translated to, 0 means not specified
+	GRPCCode    int    // GRPCCode is the gRPC status code the code is translated to, 0 means not specified
+}
+
+var (
+	codes   = make(map[int]CodeDesc)
+	codesRw sync.RWMutex
+)
+
+// RegisterCode registers the name, description, HTTP status and gRPC status code of an error code.
+// It is intended to be called from init functions, and panics if the code is 0, UnknownCode,
+// or has already been registered.
+//
+//	func init() {
+//	       errors.RegisterCode(40401, "USER_NOT_FOUND", "the user does not exist", http.StatusNotFound, 5)
+//	}
+func RegisterCode(code int, name, description string, httpStatus, grpcCode int) {
+	if code == 0 || code == UnknownCode {
+		panic(fmt.Sprintf("errors: RegisterCode called with reserved code %d", code))
+	}
+	codesRw.Lock()
+	defer codesRw.Unlock()
+	if desc, ok := codes[code]; ok {
+		panic(fmt.Sprintf("errors: RegisterCode called twice for code %d (%s)", code, desc.Name))
+	}
+	codes[code] = CodeDesc{
+		Code:        code,
+		Name:        name,
+		Description: description,
+		HTTPStatus:  httpStatus,
+		GRPCCode:    grpcCode,
+	}
+}
+
+// CodeInfo returns the description registered for code, and whether the code is registered.
+func CodeInfo(code int) (CodeDesc, bool) {
+	codesRw.RLock()
+	defer codesRw.RUnlock()
+	desc, ok := codes[code]
+	return desc, ok
+}
+
+// HTTPStatus translates the EffectiveCode of e into an HTTP status through the registered codes.
+// It returns http.StatusOK for nil errors and http.StatusInternalServerError
+// if the code is not registered or has no HTTP status.
+func HTTPStatus(e error) int {
+	if e == nil {
+		return http.StatusOK
+	}
+	if desc, ok := CodeInfo(EffectiveCode(e)); ok && desc.HTTPStatus != 0 {
+		return desc.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode translates the EffectiveCode of e into a gRPC status code through the registered codes.
+// It returns 0 (codes.OK) for nil errors and 2 (codes.Unknown)
+// if the code is not registered or has no gRPC status code.
+func GRPCCode(e error) int {
+	if e == nil {
+		return grpcOK
+	}
+	if desc, ok := CodeInfo(EffectiveCode(e)); ok && desc.GRPCCode != 0 {
+		return desc.GRPCCode
+	}
+	return grpcUnknown
+}
+
+// formatCode formats the code followed by its registered name, e.g. "40401 USER_NOT_FOUND".
+func formatCode(code int) string {
+	if desc, ok := CodeInfo(code); ok && desc.Name != "" {
+		return fmt.Sprintf("%d %s", code, desc.Name)
+	}
+	return fmt.Sprintf("%d", code)
+}
@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	RegisterCode(40401, "USER_NOT_FOUND", "the user does not exist", http.StatusNotFound, 5)
+	RegisterCode(40901, "USER_EXISTS", "the user already exists", http.StatusConflict, 0)
+}
+
+func TestRegisterCode(t *testing.T) {
+	assert.Panics(t, func() { RegisterCode(40401, "AGAIN", "", 0, 0) })
+	assert.Panics(t, func() { RegisterCode(0, "ZERO", "", 0, 0) })
+	assert.Panics(t, func() { RegisterCode(UnknownCode, "UNKNOWN", "", 0, 0) })
+
+	desc, ok := CodeInfo(40401)
+	assert.True(t, ok)
+	assert.Equal(t, CodeDesc{
+		Code:        40401,
+		Name:        "USER_NOT_FOUND",
+		Description: "the user does not exist",
+		HTTPStatus:  http.StatusNotFound,
+		GRPCCode:    5,
+	}, desc)
+
+	_, ok = CodeInfo(40499)
+	assert.False(t, ok)
+}
+
+func TestStatusMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		http int
+		grpc int
+	}{
+		{"Nil", nil, http.StatusOK, 0},
+		{"Foreign", io.EOF, http.StatusInternalServerError, 2},
+		{"Unregistered", NewWithCode(40499, "x"), http.StatusInternalServerError, 2},
+		{"Registered", Wrap(NewWithCode(40401, "x"), "y"), http.StatusNotFound, 5},
+		{"NoGRPC", NewWithCode(40901, "x"), http.StatusConflict, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.http, HTTPStatus(tc.err))
+			assert.Equal(t, tc.grpc, GRPCCode(tc.err))
+		})
+	}
+}
+
+func TestCodeFormat(t *testing.T) {
+	ResetCfg()
+	err := WrapWithCode(NewWithCode(40401, "user not found"), 40499, "load user")
+	assert.Regexp(t, "^40499, load user \\(registry_test.go:59\\)\nCaused by: 40401 USER_NOT_FOUND, user not found \\(registry_test.go:59\\)\n", fmt.Sprintf("%+v", err))
+	assert.Equal(t, "40499, load user\nCaused by: 40401, user not found", fmt.Sprintf("%v", err))
+}
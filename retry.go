@@ -0,0 +1,268 @@
+package errors
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// retryMark records whether an error layer was marked as retryable or permanent.
+type retryMark struct {
+	retryable bool          // retryable reports whether the operation may be retried
+	after     time.Duration // after is the delay requested before retrying, 0 means unspecified
+}
+
+// NewRetryable creates a new error with a stack trace, using the provided message,
+// and marks it as retryable after the given delay. A zero delay leaves the delay to the retry policy.
+func NewRetryable(msg string, after time.Duration) error {
+	return build(nil, 0, &baseError{
+		msg:   msg,
+		retry: &retryMark{retryable: true, after: after},
+	})
+}
+
+// WrapRetryable function wraps the incoming error with stack information and message,
+// and marks it as retryable after the given delay. A zero delay leaves the delay to the retry policy.
+// If the incoming err is nil, WrapRetryable will return nil.
+func WrapRetryable(e error, msg string, after time.Duration) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause: e,
+		msg:   msg,
+		retry: &retryMark{retryable: true, after: after},
+	})
+}
+
+// WithRetryable wraps the incoming error with no message and marks it as retryable after the given delay.
+// If the incoming err is nil, WithRetryable will return nil.
+func WithRetryable(e error, after time.Duration) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause: e,
+		retry: &retryMark{retryable: true, after: after},
+	})
+}
+
+// Permanent wraps the incoming error with no message and marks it as not retryable,
+// overriding the classification of the errors below it.
+// If the incoming err is nil, Permanent will return nil.
+func Permanent(e error) error {
+	if e == nil {
+		return nil
+	}
+	return build(nil, 0, &baseError{
+		cause: e,
+		retry: &retryMark{},
+	})
+}
+
+// retryableErrnos are the system errors of transient network failures.
+var retryableErrnos = []syscall.Errno{
+	syscall.ECONNRESET,
+	syscall.ECONNREFUSED,
+	syscall.ECONNABORTED,
+	syscall.EPIPE,
+	syscall.ETIMEDOUT,
+	syscall.EAGAIN,
+}
+
+// IsRetryable reports whether the operation that failed with e may succeed if retried.
+// The chain is walked outermost first and the first layer that classifies the error decides:
+//
+//	marked by NewRetryable, WrapRetryable, WithRetryable or Permanent   as marked
+//	errors implementing Retryable() bool                                as reported
+//	errors implementing Timeout() bool or Temporary() bool, e.g. net.Error   retryable if true
+//	context.DeadlineExceeded                                            retryable
+//	context.Canceled                                                    not retryable
+//	syscall.ECONNRESET, ECONNREFUSED, ECONNABORTED, EPIPE, ETIMEDOUT, EAGAIN   retryable
+//	KindUnavailable, KindTimeout, KindResourceExhausted                 retryable
+//
+// Errors that are not classified are not retryable.
+func IsRetryable(e error) bool {
+	retryable := false
+	walk(e, func(err error) bool {
+		var decided bool
+		retryable, decided = classify(err)
+		return !decided
+	})
+	return retryable
+}
+
+// classify returns the retryability of a single layer, and whether the layer classifies the error at all.
+func classify(err error) (retryable, decided bool) {
+	if b, ok := err.(*baseError); ok && b != nil && b.retry != nil {
+		return b.retry.retryable, true
+	}
+	switch err {
+	case context.DeadlineExceeded:
+		return true, true
+	case context.Canceled:
+		return false, true
+	}
+	if errno, ok := err.(syscall.Errno); ok {
+		for _, e := range retryableErrnos {
+			if errno == e {
+				return true, true
+			}
+		}
+		return false, false
+	}
+	if r, ok := err.(interface{ Retryable() bool }); ok {
+		return r.Retryable(), true
+	}
+	if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+		return true, true
+	}
+	if t, ok := err.(interface{ Temporary() bool }); ok && t.Temporary() {
+		return true, true
+	}
+	if k, ok := err.(Kinder); ok {
+		switch k.Kind() {
+		case KindUnavailable, KindTimeout, KindResourceExhausted:
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// IsTimeout reports whether any error in the chain of e is a timeout: errors implementing Timeout() bool
+// that report true, such as net.Error and context.DeadlineExceeded, syscall.ETIMEDOUT and KindTimeout.
+func IsTimeout(e error) bool {
+	return walk(e, func(err error) bool {
+		if t, ok := err.(interface{ Timeout() bool }); ok && t.Timeout() {
+			return false
+		}
+		if k, ok := err.(Kinder); ok && k.Kind() == KindTimeout {
+			return false
+		}
+		return err != syscall.ETIMEDOUT
+	})
+}
+
+// RetryAfter returns the delay requested before retrying the operation that failed with e,
+// the outermost delay on the chain wins. Errors implementing RetryAfter() time.Duration are honored.
+// It returns 0 if no delay was requested.
+func RetryAfter(e error) time.Duration {
+	var after time.Duration
+	walk(e, func(err error) bool {
+		switch x := err.(type) {
+		case *baseError:
+			if x != nil && x.retry != nil {
+				after = x.retry.after
+			}
+		case interface{ RetryAfter() time.Duration }:
+			after = x.RetryAfter()
+		}
+		return after <= 0
+	})
+	return after
+}
+
+// RetryPolicy configures the exponential backoff of Retry.
+// The zero values of the fields are replaced by the values of DefaultRetryPolicy, except Jitter.
+type RetryPolicy struct {
+	MaxAttempts  int           // MaxAttempts is the maximum number of calls, including the first one
+	InitialDelay time.Duration // InitialDelay is the delay before the second call
+	MaxDelay     time.Duration // MaxDelay caps the delay between calls
+	Multiplier   float64       // Multiplier is the factor applied to the delay after every call
+	// Jitter is the fraction of the delay randomly added or removed, e.g. 0.2 gives delays between 80% and 120%,
+	// 0 means no jitter
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the policy used for the zero fields of the policy passed to Retry.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     10 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.2,
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable according to IsRetryable,
+// or the policy runs out of attempts. Between calls it waits for an exponentially growing delay with jitter,
+// or for the RetryAfter of the error if it is longer, and gives up when ctx is done.
+// On failure, Retry returns the error of the single call, or an error joining the errors of all calls,
+// followed by the error of ctx if it interrupted the retries.
+//
+//	err := errors.Retry(ctx, errors.DefaultRetryPolicy, func(ctx context.Context) error {
+//	       return client.Call(ctx, req)
+//	})
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	var errs []error
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if attempt+1 >= policy.MaxAttempts || !IsRetryable(err) {
+			break
+		}
+		delay := policy.backoff(attempt, jitter())
+		if after := RetryAfter(err); after > delay {
+			delay = after
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			errs = append(errs, ctx.Err())
+			return Join(errs...)
+		case <-timer.C:
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return Join(errs...)
+}
+
+// withDefaults replaces the zero fields of the policy by those of DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = DefaultRetryPolicy.InitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	return p
+}
+
+var (
+	// jitterRand is the random source of the jitter, guarded by jitterMu.
+	// It is seeded explicitly because the global source is deterministic before Go 1.20.
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMu   sync.Mutex
+)
+
+// jitter returns a random number in [0, 1) used for the jitter of the retry delays.
+func jitter() float64 {
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return jitterRand.Float64()
+}
+
+// backoff returns the delay after the given zero-based attempt, random is a number in [0, 1) used for the jitter.
+func (p RetryPolicy) backoff(attempt int, random float64) time.Duration {
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	delay *= 1 + p.Jitter*(2*random-1)
+	return time.Duration(delay)
+}
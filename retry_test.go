@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type retryAfterErr struct{}
+
+func (retryAfterErr) Error() string             { return "rate limited" }
+func (retryAfterErr) Retryable() bool           { return true }
+func (retryAfterErr) RetryAfter() time.Duration { return time.Second }
+
+func TestIsRetryable(t *testing.T) {
+	netErr := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		timeout   bool
+		after     time.Duration
+	}{
+		{"nil", nil, false, false, 0},
+		{"plain", Wrap(io.EOF, "read"), false, false, 0},
+		{"new retryable", NewRetryable("busy", time.Second), true, false, time.Second},
+		{"wrap retryable", Wrap(WrapRetryable(io.EOF, "read", 0), "load"), true, false, 0},
+		{"outermost after wins", WithRetryable(NewRetryable("busy", time.Second), 2*time.Second), true, false, 2 * time.Second},
+		{"permanent", Permanent(WithRetryable(io.EOF, 0)), false, false, 0},
+		{"deadline", Wrap(context.DeadlineExceeded, "call"), true, true, 0},
+		{"canceled", fmt.Errorf("call: %w", context.Canceled), false, false, 0},
+		{"errno", Wrap(syscall.ECONNRESET, "read"), true, false, 0},
+		{"errno timeout", syscall.ETIMEDOUT, true, true, 0},
+		{"other errno", syscall.ENOENT, false, false, 0},
+		{"net error", Wrap(netErr, "dial"), true, false, 0},
+		{"kind", NewWithKind(KindUnavailable, "down"), true, false, 0},
+		{"kind timeout", Wrap(KindTimeout, "slow"), true, true, 0},
+		{"foreign", Wrap(retryAfterErr{}, "call"), true, false, time.Second},
+		{"join", Join(io.EOF, NewRetryable("busy", 0)), true, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+			assert.Equal(t, tt.timeout, IsTimeout(tt.err))
+			assert.Equal(t, tt.after, RetryAfter(tt.err))
+		})
+	}
+
+	decoded := Decode(Encode(Wrap(WithRetryable(io.EOF, 1500*time.Millisecond), "read")))
+	assert.True(t, IsRetryable(decoded))
+	assert.Equal(t, 1500*time.Millisecond, RetryAfter(decoded))
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	calls := 0
+	err := Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		if calls < 2 {
+			return NewRetryable("busy", 0)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	err = Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return WrapRetryable(io.EOF, fmt.Sprintf("attempt %d", calls), 0)
+	})
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "attempt 1\nCaused by: EOF\nattempt 2\nCaused by: EOF\nattempt 3\nCaused by: EOF", err.Error())
+	assert.True(t, Is(err, io.EOF))
+
+	// errors that are not retryable stop immediately
+	calls = 0
+	err = Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return io.EOF
+	})
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, io.EOF, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = Retry(ctx, RetryPolicy{MaxAttempts: 5, InitialDelay: time.Hour}, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return NewRetryable("busy", 0)
+	})
+	assert.Equal(t, 1, calls)
+	assert.True(t, Is(err, context.Canceled))
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2, Jitter: 0.5}
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(0, 0))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(1, 0.5))
+	assert.Equal(t, 600*time.Millisecond, policy.backoff(2, 1))
+	assert.Equal(t, time.Second, policy.backoff(10, 0.5))
+
+	assert.Equal(t, DefaultRetryPolicy.MaxAttempts, RetryPolicy{}.withDefaults().MaxAttempts)
+	assert.Equal(t, 0.0, RetryPolicy{}.withDefaults().Jitter)
+}
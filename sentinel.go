@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	sentinels   = make(map[string]error)
+	sentinelIDs = make(map[error]string)
+	types       = make(map[string]reflect.Type)
+	typeNames   = make(map[reflect.Type]string)
+	sentinelsRw sync.RWMutex
+)
+
+// RegisterSentinel registers a sentinel error under an identifier that is stable across processes.
+// When a chain containing err is encoded by Encode, the layer records id, and Decode puts err itself
+// back in its place, so that Is(decoded, err) keeps matching after the error crossed a process boundary.
+// Both processes must register the sentinel under the same id.
+// It is intended to be called from init functions, and panics if id is empty or already registered,
+// or if err is nil, not comparable or already registered.
+//
+//	var ErrUserNotFound = errors.New("user not found")
+//
+//	func init() {
+//	       errors.RegisterSentinel("user.not_found", ErrUserNotFound)
+//	}
+func RegisterSentinel(id string, err error) {
+	if id == "" || err == nil {
+		panic("errors: RegisterSentinel called with an empty id or a nil error")
+	}
+	if !reflect.TypeOf(err).Comparable() {
+		panic(fmt.Sprintf("errors: RegisterSentinel called with a non comparable error of type %T", err))
+	}
+	sentinelsRw.Lock()
+	defer sentinelsRw.Unlock()
+	if _, ok := sentinels[id]; ok {
+		panic(fmt.Sprintf("errors: RegisterSentinel called twice for id %q", id))
+	}
+	if other, ok := sentinelIDs[err]; ok {
+		panic(fmt.Sprintf("errors: RegisterSentinel called twice for the same error, already registered as %q", other))
+	}
+	sentinels[id] = err
+	sentinelIDs[err] = id
+}
+
+// RegisterType registers the concrete type of prototype under a name that is stable across processes.
+// When a chain containing an error of that type is encoded by Encode, the layer records name and the
+// JSON encoding of the error, and Decode rebuilds a value of the same type from it, so that As keeps
+// finding the concrete type after the error crossed a process boundary.
+// The type should marshal its state to JSON, unexported fields are lost unless it implements
+// json.Marshaler and json.Unmarshaler. The rebuilt value does not wrap the errors below it.
+// It is intended to be called from init functions, and panics if name is empty or already registered,
+// or if the type of prototype is already registered.
+//
+//	func init() {
+//	       errors.RegisterType("quota.exceeded", &QuotaError{})
+//	}
+func RegisterType(name string, prototype error) {
+	if name == "" || prototype == nil {
+		panic("errors: RegisterType called with an empty name or a nil prototype")
+	}
+	t := reflect.TypeOf(prototype)
+	sentinelsRw.Lock()
+	defer sentinelsRw.Unlock()
+	if _, ok := types[name]; ok {
+		panic(fmt.Sprintf("errors: RegisterType called twice for name %q", name))
+	}
+	if other, ok := typeNames[t]; ok {
+		panic(fmt.Sprintf("errors: RegisterType called twice for type %s, already registered as %q", t, other))
+	}
+	types[name] = t
+	typeNames[t] = name
+}
+
+// sentinelID returns the identifier err is registered under by RegisterSentinel.
+func sentinelID(err error) (string, bool) {
+	if err == nil || !reflect.TypeOf(err).Comparable() {
+		return "", false
+	}
+	sentinelsRw.RLock()
+	defer sentinelsRw.RUnlock()
+	id, ok := sentinelIDs[err]
+	return id, ok
+}
+
+// sentinel returns the error registered under id by RegisterSentinel.
+func sentinel(id string) (error, bool) {
+	sentinelsRw.RLock()
+	defer sentinelsRw.RUnlock()
+	err, ok := sentinels[id]
+	return err, ok
+}
+
+// typeName returns the name the type of err is registered under by RegisterType.
+func typeName(err error) (string, bool) {
+	sentinelsRw.RLock()
+	defer sentinelsRw.RUnlock()
+	name, ok := typeNames[reflect.TypeOf(err)]
+	return name, ok
+}
+
+// newOfType rebuilds an error of the type registered under name from its JSON encoding.
+// It returns false if the name is not registered or data cannot be decoded into the type.
+func newOfType(name string, data []byte) (error, bool) {
+	sentinelsRw.RLock()
+	t, ok := types[name]
+	sentinelsRw.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	var v reflect.Value
+	if t.Kind() == reflect.Ptr {
+		v = reflect.New(t.Elem())
+		if err := json.Unmarshal(data, v.Interface()); err != nil {
+			return nil, false
+		}
+	} else {
+		ptr := reflect.New(t)
+		if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+			return nil, false
+		}
+		v = ptr.Elem()
+	}
+	// the registered type implements error
+	return v.Interface().(error), true
+}
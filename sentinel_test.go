@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errSentinelNotFound = New("user not found")
+	errSentinelTimeout  = errors.New("upstream timeout")
+)
+
+type quotaError struct {
+	Limit int `json:"limit"`
+}
+
+func (q *quotaError) Error() string { return fmt.Sprintf("quota of %d exceeded", q.Limit) }
+
+type throttleError struct {
+	Reason string `json:"reason"`
+}
+
+func (t throttleError) Error() string { return "throttled: " + t.Reason }
+
+func init() {
+	RegisterSentinel("user.not_found", errSentinelNotFound)
+	RegisterSentinel("upstream.timeout", errSentinelTimeout)
+	RegisterType("quota.exceeded", &quotaError{})
+	RegisterType("throttle", throttleError{})
+}
+
+func TestRegisterSentinel(t *testing.T) {
+	assert.Panics(t, func() { RegisterSentinel("user.not_found", New("again")) })
+	assert.Panics(t, func() { RegisterSentinel("other", errSentinelNotFound) })
+	assert.Panics(t, func() { RegisterSentinel("", New("empty")) })
+	assert.Panics(t, func() { RegisterSentinel("nil", nil) })
+	assert.Panics(t, func() { RegisterType("quota.exceeded", throttleError{}) })
+	assert.Panics(t, func() { RegisterType("quota", &quotaError{}) })
+
+	origin := Wrap(Wrap(errSentinelNotFound, "load user"), "handle request")
+	err := Decode(Encode(origin))
+	assert.Equal(t, origin.Error(), err.Error())
+	assert.True(t, Is(err, errSentinelNotFound))
+
+	// foreign sentinels are restored as well
+	err = Decode(Encode(Wrap(fmt.Errorf("call upstream: %w", errSentinelTimeout), "fetch")))
+	assert.True(t, Is(err, errSentinelTimeout))
+	assert.False(t, Is(err, errors.New("upstream timeout")))
+}
+
+func TestRegisterType(t *testing.T) {
+	origin := Wrap(Join(&quotaError{Limit: 10}, throttleError{Reason: "burst"}), "call upstream")
+	err := Decode(Encode(origin))
+	assert.Equal(t, origin.Error(), err.Error())
+
+	var quota *quotaError
+	assert.True(t, As(err, &quota))
+	assert.Equal(t, 10, quota.Limit)
+
+	var throttle throttleError
+	assert.True(t, As(err, &throttle))
+	assert.Equal(t, "burst", throttle.Reason)
+
+	// unregistered names degrade to opaque errors
+	err = Decode([]byte(`{"version":1,"error":{"message":"quota of 5 exceeded","type":"*pkg.Quota","registered_type":"unknown","data":{}}}`))
+	assert.False(t, As(err, &quota))
+	assert.Equal(t, "quota of 5 exceeded", err.Error())
+}
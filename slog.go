@@ -0,0 +1,183 @@
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+)
+
+// LogValue implements the slog.LogValuer interface, so that slog.Any("err", err) logs the chain as a group:
+//
+//	code      the code of the layer, omitted if 0
+//	kind      the kind of the layer, omitted if empty
+//	msg       the message of the layer
+//	fields    the key/value pairs of the layer, omitted if there are none
+//	metadata  the metadata extracted from a context, omitted if there is none
+//	stack     the stack of the layer, only if Config.LogStack is set
+//	cause     the group of the next layer of the chain
+func (b *baseError) LogValue() slog.Value {
+	return logValue(b)
+}
+
+// NewLogHandler returns a slog.Handler that passes records to h after expanding every attribute
+// whose value is an error with an error of this package on its chain, e.g. fmt.Errorf("load: %w", err),
+// into the same group as LogValue. Attributes inside groups are expanded as well.
+//
+//	logger := slog.New(errors.NewLogHandler(slog.NewJSONHandler(os.Stderr, nil)))
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return &logHandler{handler: h}
+}
+
+// logHandler is the slog.Handler returned by NewLogHandler.
+type logHandler struct {
+	handler slog.Handler
+}
+
+// Enabled implements the slog.Handler interface.
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle implements the slog.Handler interface.
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(expandAttr(a))
+		return true
+	})
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		expanded = append(expanded, expandAttr(a))
+	}
+	return &logHandler{handler: h.handler.WithAttrs(expanded)}
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{handler: h.handler.WithGroup(name)}
+}
+
+// expandAttr replaces the errors of this package in a by their log values.
+func expandAttr(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok && hasOwnError(err) {
+			return slog.Attr{Key: a.Key, Value: logValue(err)}
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, attr := range group {
+			attrs = append(attrs, expandAttr(attr))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	}
+	return a
+}
+
+// hasOwnError reports whether there is an error of this package on the chain of e.
+func hasOwnError(e error) bool {
+	return walk(e, func(err error) bool {
+		switch err.(type) {
+		case *baseError, *joinError:
+			return false
+		}
+		return true
+	})
+}
+
+// logValue returns the group describing the chain of e.
+// Foreign errors are described by their message and type, the chain below them is only expanded
+// if it contains an error of this package, since their message already contains its text.
+func logValue(e error) slog.Value {
+	switch x := e.(type) {
+	case *baseError:
+		if x == nil {
+			return slog.GroupValue(slog.String("msg", Success))
+		}
+		var attrs []slog.Attr
+		if x.code != 0 {
+			attrs = append(attrs, slog.Int("code", x.code))
+		}
+		if x.kind != "" {
+			attrs = append(attrs, slog.String("kind", string(x.kind)))
+		}
+		attrs = append(attrs, slog.String("msg", x.msg))
+		if len(x.fields) > 0 {
+			fields := make([]slog.Attr, 0, len(x.fields))
+			for _, f := range x.fields {
+				fields = append(fields, slog.Any(f.Key, f.Value))
+			}
+			attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fields...)})
+		}
+		if len(x.meta) > 0 {
+			keys := make([]string, 0, len(x.meta))
+			for key := range x.meta {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			meta := make([]slog.Attr, 0, len(keys))
+			for _, key := range keys {
+				meta = append(meta, slog.String(key, x.meta[key]))
+			}
+			attrs = append(attrs, slog.Attr{Key: "metadata", Value: slog.GroupValue(meta...)})
+		}
+		if x.stack != nil && x.config().LogStack {
+			attrs = append(attrs, slog.Any("stack", logFrames(*x.stack)))
+		}
+		if x.cause != nil && (!x.inline || hasOwnError(x.cause)) {
+			attrs = append(attrs, slog.Attr{Key: "cause", Value: logValue(x.cause)})
+		}
+		return slog.GroupValue(attrs...)
+	case *spawnError:
+		return logValue(x.err)
+	case *joinError:
+		branches := make([]slog.Attr, 0, len(x.errs))
+		for i, err := range x.errs {
+			branches = append(branches, slog.Attr{Key: strconv.Itoa(i), Value: logValue(err)})
+		}
+		return slog.GroupValue(slog.String("msg", x.Error()), slog.Attr{Key: "errors", Value: slog.GroupValue(branches...)})
+	}
+
+	var attrs []slog.Attr
+	if c, ok := e.(Coder); ok {
+		attrs = append(attrs, slog.Int("code", c.Code()))
+	}
+	attrs = append(attrs, slog.String("msg", e.Error()), slog.String("type", fmt.Sprintf("%T", e)))
+	var cause error
+	switch x := e.(type) {
+	case interface{ Unwrap() error }:
+		cause = x.Unwrap()
+	case interface{ Unwrap() []error }:
+		cause = Join(x.Unwrap()...)
+	case interface{ Cause() error }:
+		cause = x.Cause()
+	}
+	if cause != nil && hasOwnError(cause) {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: logValue(cause)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// logFrames formats the frames of st as "function file:line".
+func logFrames(st StackTrace) []string {
+	var frames []string
+	for _, frame := range st.Frames() {
+		if frame.Elided() > 0 {
+			frames = append(frames, fmt.Sprintf("%v", frame))
+			continue
+		}
+		frames = append(frames, fmt.Sprintf("%s %s:%d", frame.Function(), frame.File(), frame.Line()))
+	}
+	return frames
+}
@@ -0,0 +1,103 @@
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func logJSON(t *testing.T, h func(slog.Handler) slog.Handler, args ...interface{}) map[string]interface{} {
+	var buffer bytes.Buffer
+	logger := slog.New(h(slog.NewJSONHandler(&buffer, nil)))
+	logger.Error("failed", args...)
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &doc))
+	return doc
+}
+
+func TestLogValue(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		LogStack:            true,
+	})
+	defer ResetCfg()
+
+	plain := func(h slog.Handler) slog.Handler { return h }
+	err := WrapWithFields(WrapWithCode(io.EOF, 40401, "read user"), "load user", "user_id", 42)
+	doc := logJSON(t, plain, "err", err)
+	assert.Equal(t, map[string]interface{}{
+		"msg":    "load user",
+		"fields": map[string]interface{}{"user_id": float64(42)},
+		"cause": map[string]interface{}{
+			"code": float64(40401),
+			"msg":  "read user",
+			"stack": []interface{}{
+				fmt.Sprintf("github.com/morrisxyang/errors.TestLogValue %s:%d",
+					err.(*baseError).cause.(*baseError).StackTrace().Frames()[0].File(), 35),
+			},
+			"cause": map[string]interface{}{"msg": "EOF", "type": "*errors.errorString"},
+		},
+	}, doc["err"])
+
+	// without the handler, foreign wrappers hide the chain
+	doc = logJSON(t, plain, "err", fmt.Errorf("handle: %w", err))
+	assert.Equal(t, "handle: load user: 40401, read user: EOF", doc["err"])
+}
+
+func TestLogHandler(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	err := fmt.Errorf("handle: %w", Join(NewWithCode(40901, "exists"), io.EOF))
+	doc := logJSON(t, NewLogHandler, slog.Group("req", slog.Any("err", err)), "plain", io.EOF)
+	assert.Equal(t, map[string]interface{}{
+		"msg":  "handle: 40901, exists\nEOF",
+		"type": "*fmt.wrapError",
+		"cause": map[string]interface{}{
+			"msg": "40901, exists\nEOF",
+			"errors": map[string]interface{}{
+				"0": map[string]interface{}{"code": float64(40901), "msg": "exists"},
+				"1": map[string]interface{}{"msg": "EOF", "type": "*errors.errorString"},
+			},
+		},
+	}, doc["req"].(map[string]interface{})["err"])
+	assert.Equal(t, "EOF", doc["plain"])
+
+	var buffer bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buffer, nil))).With("err", err).WithGroup("g")
+	logger.Info("done")
+	assert.Contains(t, buffer.String(), `"err":{"msg":"handle: 40901, exists\nEOF","type":"*fmt.wrapError","cause":`)
+}
+
+func TestLogValueInline(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		StackPolicy:         StackNever,
+	})
+	defer ResetCfg()
+
+	plain := func(h slog.Handler) slog.Handler { return h }
+	// inline causes of this package are logged even without a stack
+	doc := logJSON(t, plain, "err", Errorf("load: %w", NewWithCode(404, "not found")))
+	assert.Equal(t, map[string]interface{}{
+		"msg":   "load: 404, not found",
+		"cause": map[string]interface{}{"code": float64(404), "msg": "not found"},
+	}, doc["err"])
+
+	// foreign inline causes are already part of the message
+	doc = logJSON(t, plain, "err", Errorf("read: %w", io.EOF))
+	assert.Equal(t, map[string]interface{}{"msg": "read: EOF"}, doc["err"])
+}
@@ -3,12 +3,274 @@ package errors
 import (
 	"fmt"
 	"io"
+	"path"
 	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
 )
 
+// Frame represents a single resolved frame of a StackTrace.
+// When Config.SkipPackages drops frames, consecutive dropped frames are represented by a single placeholder Frame,
+// see Elided.
+type Frame struct {
+	frame  runtime.Frame
+	elided int // elided is the number of dropped frames the placeholder stands for
+}
+
+// Elided returns the number of consecutive frames dropped by Config.SkipPackages this placeholder stands for,
+// or 0 if the frame is a real frame.
+func (f Frame) Elided() int { return f.elided }
+
+// PC returns the program counter of the frame.
+func (f Frame) PC() uintptr { return f.frame.PC }
+
+// Function returns the fully qualified function name of the frame,
+// e.g. "github.com/morrisxyang/errors.(*baseError).Error".
+func (f Frame) Function() string { return f.frame.Function }
+
+// Package returns the import path of the package the function belongs to,
+// e.g. "github.com/morrisxyang/errors".
+func (f Frame) Package() string {
+	pkg, _ := splitFunction(f.frame.Function)
+	return pkg
+}
+
+// Receiver returns the receiver type of the method, e.g. "*baseError" or "StackTrace".
+// It returns an empty string for plain functions and closures.
+func (f Frame) Receiver() string {
+	_, name := splitFunction(f.frame.Function)
+	if strings.HasPrefix(name, "(") {
+		if i := strings.Index(name, ")"); i > 0 {
+			return name[1:i]
+		}
+		return ""
+	}
+	parts := strings.Split(name, ".")
+	// "T.M" is a value receiver method, while "F.func1" or "F.1" are closures of F.
+	if len(parts) < 2 || isClosureName(parts[1]) {
+		return ""
+	}
+	return parts[0]
+}
+
+// Name returns the function name without the package path, e.g. "(*baseError).Error".
+func (f Frame) Name() string {
+	_, name := splitFunction(f.frame.Function)
+	return name
+}
+
+// File returns the absolute path of the source file of the frame,
+// or the trimmed path if Config.TrimPaths is set.
+func (f Frame) File() string { return f.frame.File }
+
+// RelFile returns the path of the source file relative to the root of the module containing it,
+// e.g. "httperr/problem.go". If the module cannot be determined from the build information,
+// the package import path is used as the directory, e.g. "runtime/proc.go".
+// Frames of the main package are relative to the main module as well, e.g. "cmd/tool/main.go",
+// or give the file name if the main package is not part of a module, e.g. "main.go".
+func (f Frame) RelFile() string {
+	if f.frame.File == "" {
+		return ""
+	}
+	base := path.Base(f.frame.File)
+	pkg := f.importPath()
+	if pkg == "" {
+		return base
+	}
+	if mod := modulePath(pkg); mod != "" {
+		sub := strings.TrimPrefix(strings.TrimPrefix(pkg, mod), "/")
+		return path.Join(sub, base)
+	}
+	return path.Join(pkg, base)
+}
+
+// importPath returns the import path of the package of the frame. Function names only give "main"
+// for the main package, its import path is taken from the build information if it is part of a module.
+// It returns an empty string if the import path is unknown.
+func (f Frame) importPath() string {
+	pkg := f.Package()
+	if pkg != "main" {
+		return pkg
+	}
+	if main := mainPackage(); modulePath(main) != "" {
+		return main
+	}
+	return ""
+}
+
+// Line returns the source line number of the frame.
+func (f Frame) Line() int { return f.frame.Line }
+
+// Format formats the frame according to the fmt.Formatter interface.
+//
+//	%s    source file base name
+//	%d    source line
+//	%n    function name without the package path
+//	%v    equivalent to %s:%d
+//
+// Format accepts flags that alter the printing of some verbs, as follows:
+//
+//	%+s   function name and absolute path of source file separated by \n\t (<funcname>\n\t<path>)
+//	%+v   equivalent to %+s:%d
+//
+// A placeholder for elided frames prints "... N frames elided" for every verb.
+func (f Frame) Format(s fmt.State, verb rune) {
+	if f.elided > 0 {
+		_, _ = fmt.Fprintf(s, "... %d frames elided", f.elided)
+		return
+	}
+	switch verb {
+	case 's':
+		switch {
+		case s.Flag('+'):
+			io.WriteString(s, f.Function())
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, f.File())
+		default:
+			io.WriteString(s, path.Base(f.File()))
+		}
+	case 'd':
+		_, _ = fmt.Fprintf(s, "%d", f.Line())
+	case 'n':
+		io.WriteString(s, f.Name())
+	case 'v':
+		f.Format(s, 's')
+		io.WriteString(s, ":")
+		f.Format(s, 'd')
+	default:
+		_, _ = fmt.Fprintf(s, "unsupported format: %%!%c, use %%s: ", verb)
+		f.Format(s, 's')
+	}
+}
+
+// frameOf resolves a program counter captured by runtime.Callers into the Frame of its outermost function,
+// with the path trimming of cfg applied. A nil cfg means the global configuration.
+func frameOf(pc uintptr, cfg *Config) Frame {
+	if pc == 0 {
+		return Frame{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if cfg == nil {
+		cfg = GetCfg()
+	}
+	return resolve(frame, cfg)
+}
+
+// splitFunction splits a fully qualified function name into the package path and the rest of the name.
+func splitFunction(function string) (pkg, name string) {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return "", function
+	}
+	return function[:slash+1+dot], function[slash+1+dot+1:]
+}
+
+// isClosureName reports whether the name element is generated by the compiler for a closure.
+func isClosureName(s string) bool {
+	s = strings.TrimPrefix(s, "func")
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	modulesOnce sync.Once
+	modules     []string
+	mainPath    string // mainPath is the import path of the main package, e.g. "example.com/tool/cmd/tool"
+)
+
+// loadModules reads the modules of the build and the import path of the main package from the build information.
+func loadModules() {
+	modulesOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		// test binaries are built as a main package named after the tested package
+		mainPath = strings.TrimSuffix(info.Path, ".test")
+		if info.Main.Path != "" {
+			modules = append(modules, info.Main.Path)
+		}
+		for _, dep := range info.Deps {
+			modules = append(modules, dep.Path)
+		}
+	})
+}
+
+// mainPackage returns the import path of the main package, or an empty string if no build information is available.
+func mainPackage() string {
+	loadModules()
+	return mainPath
+}
+
+// modulePath returns the path of the module in the build that provides the package,
+// or an empty string if no build information is available.
+func modulePath(pkg string) string {
+	loadModules()
+	var longest string
+	for _, mod := range modules {
+		if (pkg == mod || strings.HasPrefix(pkg, mod+"/")) && len(mod) > len(longest) {
+			longest = mod
+		}
+	}
+	return longest
+}
+
 // StackTrace is stack of Frames from innermost (newest) to outermost (oldest).
+// It keeps the raw program counters and resolves them on every access,
+// so it can be formatted and inspected any number of times.
 type StackTrace struct {
-	runtime.Frames
+	pcs []uintptr
+	cfg *Config // cfg is the configuration used to filter the frames, nil means the global configuration
+	// remote are the frames of a stack recorded by another process, decoded by Decode
+	remote []Frame
+}
+
+// Remote reports whether the stack was recorded by another process and decoded by Decode.
+func (st StackTrace) Remote() bool { return st.remote != nil }
+
+// Frames resolves the program counters into Frames, from innermost (newest) to outermost (oldest).
+// The frame filters of the configuration are applied, see Config.TrimPaths and Config.SkipPackages.
+func (st StackTrace) Frames() []Frame {
+	if st.remote != nil {
+		return append([]Frame(nil), st.remote...)
+	}
+	if len(st.pcs) == 0 {
+		return nil
+	}
+	cfg := st.cfg
+	if cfg == nil {
+		cfg = GetCfg()
+	}
+	frames := make([]Frame, 0, len(st.pcs))
+	iter := runtime.CallersFrames(st.pcs)
+	for {
+		frame, more := iter.Next()
+		if frame.PC > 0 {
+			if skipped(frame.Function, cfg.SkipPackages) {
+				// collapse consecutive dropped frames into a single placeholder
+				if n := len(frames); n > 0 && frames[n-1].elided > 0 {
+					frames[n-1].elided++
+				} else {
+					frames = append(frames, Frame{elided: 1})
+				}
+			} else {
+				frames = append(frames, resolve(frame, cfg))
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
 }
 
 // Format formats the stack of Frames according to the fmt.Formatter interface.
@@ -19,20 +281,22 @@ type StackTrace struct {
 // Format accepts flags that alter the printing of some verbs, as follows:
 //
 //	%+v   Prints filename, function, and line number for each Frame in the stack.
+//	      Remote stacks are introduced by a "(remote)" line.
 func (st StackTrace) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		switch {
 		case s.Flag('+'):
-			for {
-				frame, more := st.Frames.Next()
-				if frame.PC > 0 {
-					fmt.Fprintf(s, "\n%s", frame.Function)
-					fmt.Fprintf(s, "\n\t%s:%d", frame.File, frame.Line)
-				}
-				if !more {
-					break
+			if st.Remote() {
+				io.WriteString(s, "\n(remote)")
+			}
+			for _, frame := range st.Frames() {
+				if frame.Elided() > 0 {
+					fmt.Fprintf(s, "\n%v", frame)
+					continue
 				}
+				fmt.Fprintf(s, "\n%s", frame.Function())
+				fmt.Fprintf(s, "\n\t%s:%d", frame.File(), frame.Line())
 			}
 		default:
 			st.formatSlice(s, verb)
@@ -49,13 +313,15 @@ func (st StackTrace) Format(s fmt.State, verb rune) {
 // Frame, only valid when called with '%s' or '%v'.
 func (st StackTrace) formatSlice(s fmt.State, verb rune) {
 	io.WriteString(s, "[")
-	for {
-		frame, more := st.Frames.Next()
-		fmt.Fprintf(s, "%s", frame.Function)
-		if !more {
-			break
+	for i, frame := range st.Frames() {
+		if i > 0 {
+			io.WriteString(s, " ")
+		}
+		if frame.Elided() > 0 {
+			io.WriteString(s, "...")
+			continue
 		}
-		io.WriteString(s, " ")
+		io.WriteString(s, frame.Function())
 	}
 	io.WriteString(s, "]")
 }
@@ -68,16 +68,11 @@ var initCallers = func() *StackTrace {
 	var pcs [maxDepth]uintptr
 	n := runtime.Callers(2, pcs[:])
 
-	var stack *runtime.Frames
 	cfg := GetCfg()
 	if cfg.StackDepth > 0 && cfg.StackDepth < n {
-		stack = runtime.CallersFrames(pcs[0:cfg.StackDepth])
-	} else {
-		// 转换为 errors.StackTrace
-		stack = runtime.CallersFrames(pcs[0:n])
+		n = cfg.StackDepth
 	}
-
-	return &StackTrace{*stack}
+	return &StackTrace{pcs: pcs[0:n]}
 }()
 
 func TestStackTraceFormat(t *testing.T) {
@@ -162,3 +157,75 @@ func testFormatRegexp(t *testing.T, n int, arg interface{}, format, want string)
 	}
 	t.Logf("success test %d: fmt.Sprintf(%q, err):\n got: %q\nwant: %q", n+1, format, got, want)
 }
+
+func TestStackTraceReplay(t *testing.T) {
+	err := New("replay")
+	first := fmt.Sprintf("%+v", err)
+	second := fmt.Sprintf("%+v", err)
+	if first != second {
+		t.Errorf("expected identical output on repeated formatting:\n first: %q\nsecond: %q", first, second)
+	}
+
+	st := err.(interface{ StackTrace() StackTrace }).StackTrace()
+	frames := st.Frames()
+	if len(frames) == 0 {
+		t.Fatal("expected frames, got none")
+	}
+	if got, want := frames[0].Function(), "github.com/morrisxyang/errors.TestStackTraceReplay"; got != want {
+		t.Errorf("Frames()[0].Function(): got %q, want %q", got, want)
+	}
+	if again := st.Frames(); len(again) != len(frames) {
+		t.Errorf("Frames(): got %d frames on second call, want %d", len(again), len(frames))
+	}
+}
+
+type frameRecorder struct{}
+
+func (*frameRecorder) pointerMethod() Frame { return New("frame").(*baseError).stack.Frames()[0] }
+
+func (frameRecorder) valueMethod() Frame { return New("frame").(*baseError).stack.Frames()[0] }
+
+func TestFrame(t *testing.T) {
+	closure := func() Frame { return New("frame").(*baseError).stack.Frames()[0] }
+	tests := []struct {
+		frame    Frame
+		receiver string
+		name     string
+	}{
+		{(&frameRecorder{}).pointerMethod(), "*frameRecorder", "(*frameRecorder).pointerMethod"},
+		{frameRecorder{}.valueMethod(), "frameRecorder", "frameRecorder.valueMethod"},
+		{closure(), "", "TestFrame.func1"},
+	}
+	for i, tt := range tests {
+		if got := tt.frame.Package(); got != "github.com/morrisxyang/errors" {
+			t.Errorf("test %d: Package(): got %q", i+1, got)
+		}
+		if got := tt.frame.Receiver(); got != tt.receiver {
+			t.Errorf("test %d: Receiver(): got %q, want %q", i+1, got, tt.receiver)
+		}
+		if got := tt.frame.Name(); got != tt.name {
+			t.Errorf("test %d: Name(): got %q, want %q", i+1, got, tt.name)
+		}
+		if got := tt.frame.RelFile(); got != "stack_test.go" {
+			t.Errorf("test %d: RelFile(): got %q, want %q", i+1, got, "stack_test.go")
+		}
+		if got := tt.frame.Function(); got != tt.frame.Package()+"."+tt.name {
+			t.Errorf("test %d: Function(): got %q", i+1, got)
+		}
+	}
+
+	for _, function := range []string{"main.main", "main.run.func1"} {
+		frame := Frame{frame: runtime.Frame{Function: function, File: "/src/app/cmd/tool/main.go", Line: 22}}
+		if got := frame.RelFile(); got != "main.go" {
+			t.Errorf("%s: RelFile(): got %q, want %q", function, got, "main.go")
+		}
+	}
+
+	frame := tests[0].frame
+	testFormatRegexp(t, 0, frame, "%s", `^stack_test.go$`)
+	testFormatRegexp(t, 1, frame, "%d", `^\d+$`)
+	testFormatRegexp(t, 2, frame, "%n", `^\(\*frameRecorder\)\.pointerMethod$`)
+	testFormatRegexp(t, 3, frame, "%v", `^stack_test.go:\d+$`)
+	testFormatRegexp(t, 4, frame, "%+v",
+		`^github.com/morrisxyang/errors.\(\*frameRecorder\).pointerMethod\n\t.+/stack_test.go:\d+$`)
+}
@@ -0,0 +1,17 @@
+// Command trimmain prints an error created in the main package with Config.TrimPaths set, see TestTrimPathsMain.
+package main
+
+import (
+	"fmt"
+
+	"github.com/morrisxyang/errors"
+)
+
+func main() {
+	errors.SetCfg(&errors.Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		TrimPaths:           true,
+	})
+	fmt.Printf("%+v\n", errors.New("boom"))
+}
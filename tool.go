@@ -2,24 +2,100 @@ package errors
 
 import (
 	"runtime"
+	"strings"
 )
 
-// callers function retrieves the stack trace of the current goroutine.
-func callers() *StackTrace {
-	// constant to limit the depth of the stack trace
-	const maxDepth = 64
-	var pcs [maxDepth]uintptr
-	n := runtime.Callers(3, pcs[:])
+// initialDepth is the initial size of the buffer used to capture stacks of unlimited depth.
+const initialDepth = 64
+
+// callers function retrieves the stack trace of the current goroutine, starting at the caller of the function
+// calling callers, skip is the number of additional stack frames to skip on top of Config.CallerSkip.
+// The depth of the stack follows cfg, a nil cfg means the global configuration.
+func callers(cfg *Config, skip int) *StackTrace {
+	c := cfg
+	if c == nil {
+		c = GetCfg()
+	}
+	// if StackDepth is set, only that many frames are captured, otherwise all of them
+	pcs := capture(3+skip+c.CallerSkip, c.StackDepth)
+	return &StackTrace{pcs: pcs, cfg: cfg}
+}
+
+// capture returns the program counters of the current goroutine, skipping the first skip frames
+// as runtime.Callers does. If depth is positive, at most depth program counters are returned,
+// otherwise the buffer grows until the whole stack fits.
+func capture(skip, depth int) []uintptr {
+	size := depth
+	if size <= 0 {
+		size = initialDepth
+	}
+	for {
+		pcs := make([]uintptr, size)
+		// one more frame is skipped for capture itself
+		n := runtime.Callers(skip+1, pcs)
+		if n < size || depth > 0 {
+			return pcs[:n:n]
+		}
+		size *= 2
+	}
+}
+
+// caller function retrieves the program counter of the caller of the function calling caller,
+// skip is the number of additional stack frames to skip on top of Config.CallerSkip.
+// It returns 0 if the stack is not deep enough.
+func caller(cfg *Config, skip int) uintptr {
+	if cfg == nil {
+		cfg = GetCfg()
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(3+skip+cfg.CallerSkip, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// panicCallers function retrieves the stack trace of a panicking goroutine, starting at the panic site.
+// The frames of the deferred functions and of the runtime panic machinery are skipped.
+// If the goroutine is not panicking, the stack starts at the caller of Recover or RecoverValue.
+func panicCallers() *StackTrace {
+	pcs := capture(3, 0)
+	n := len(pcs)
+
+	// skip Recover or RecoverValue
+	start := 1
+	for i := 0; i < n; i++ {
+		if function(pcs[i]) == "runtime.gopanic" {
+			start = i + 1
+			// runtime helpers such as runtime.sigpanic and runtime.panicmem raise panics on behalf of the caller
+			for start < n && strings.HasPrefix(function(pcs[start]), "runtime.") {
+				start++
+			}
+			break
+		}
+	}
+	if start > n {
+		start = n
+	}
+	pcs = pcs[start:]
 
 	cfg := GetCfg()
-	var stack *runtime.Frames
 	// if StackDepth is set and less than total number of frames then limit stack trace depth
-	if cfg.StackDepth > 0 && cfg.StackDepth < n {
-		stack = runtime.CallersFrames(pcs[0:cfg.StackDepth])
-	} else {
-		// otherwise, return all frames
-		stack = runtime.CallersFrames(pcs[0:n])
+	if cfg.StackDepth > 0 && cfg.StackDepth < len(pcs) {
+		pcs = pcs[:cfg.StackDepth:cfg.StackDepth]
 	}
+	return &StackTrace{pcs: pcs}
+}
 
-	return &StackTrace{*stack}
+// function returns the name of the outermost function of the frames at pc.
+func function(pc uintptr) string {
+	var name string
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		name = frame.Function
+		if !more {
+			break
+		}
+	}
+	return name
 }
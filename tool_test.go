@@ -2,6 +2,7 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -15,6 +16,62 @@ func TestDepth(t *testing.T) {
 	})
 
 	fmt.Printf("%+v\n", New("callers"))
-	assert.Regexp(t, "^callers\ngithub.com/morrisxyang/errors.TestDepth\n\t.*tool_test.go:19$",
+	assert.Regexp(t, "^callers \\(tool_test.go:20\\)\ngithub.com/morrisxyang/errors.TestDepth\n\t.*tool_test.go:20$",
 		fmt.Sprintf("%+v", New("callers")))
 }
+
+func recurse(n int, fn func() error) error {
+	if n == 0 {
+		return fn()
+	}
+	return recurse(n-1, fn)
+}
+
+func TestUnlimitedDepth(t *testing.T) {
+	defer ResetCfg()
+	for _, depth := range []int{0, 100} {
+		SetCfg(&Config{
+			StackDepth:          depth,
+			ErrorConnectionFlag: ": ",
+		})
+		err := recurse(200, func() error { return New("deep") })
+		frames := err.(*baseError).StackTrace().Frames()
+		if depth == 0 {
+			assert.Greater(t, len(frames), 200)
+			assert.Equal(t, "runtime.goexit", frames[len(frames)-1].Function())
+		} else {
+			assert.Len(t, frames, depth)
+		}
+	}
+}
+
+func newHelper(msg string) error { return NewSkip(1, msg) }
+
+func wrapHelper(e error, msg string) error { return WrapSkip(1, e, msg) }
+
+func TestSkip(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	err := newHelper("skip")
+	assert.Equal(t, "github.com/morrisxyang/errors.TestSkip", err.(*baseError).StackTrace().Frames()[0].Function())
+	assert.Equal(t, "github.com/morrisxyang/errors.TestSkip", err.(*baseError).Location().Function())
+
+	err = wrapHelper(errors.New("EOF"), "skip")
+	assert.Equal(t, "github.com/morrisxyang/errors.TestSkip", err.(*baseError).StackTrace().Frames()[0].Function())
+	assert.Nil(t, WrapSkip(1, nil, "skip"))
+
+	f := NewFactory(&Config{
+		StackDepth: 1,
+		CallerSkip: 1,
+	})
+	helper := func() error { return f.New("skip") }
+	err = helper()
+	assert.Equal(t, "github.com/morrisxyang/errors.TestSkip", err.(*baseError).StackTrace().Frames()[0].Function())
+	assert.Equal(t, "github.com/morrisxyang/errors.TestSkip", err.(*baseError).Location().Function())
+	err = func() error { return f.WrapSkip(1, errors.New("EOF"), "skip") }()
+	assert.Equal(t, "testing.tRunner", err.(*baseError).Location().Function())
+}
@@ -0,0 +1,167 @@
+package errors
+
+import (
+	"reflect"
+)
+
+// maxWalkDepth bounds the depth of a walk, in case a cycle goes through errors that cannot be compared.
+const maxWalkDepth = 1024
+
+// Layer describes a single error of a chain, as returned by Layers.
+type Layer struct {
+	Error error // Error is the layer itself
+	Depth int   // Depth is the distance from the outermost error, 0 for the outermost error
+	// Code is the code of the layer if it implements Coder, UnknownCode otherwise
+	Code int
+	// Kind is the kind of the layer if it implements Kinder, an empty Kind otherwise
+	Kind Kind
+	// Msg is the message of the layer for errors of this package, the text of Error() for foreign errors
+	Msg string
+	// Location is the call that created the layer, the zero Frame for foreign errors
+	Location Frame
+	// Fields are the key/value pairs attached to the layer
+	Fields []Field
+}
+
+// Walk calls fn for err and every error reachable from it, depth first and outermost first,
+// with the distance of the layer from err. The chain is followed through Unwrap() error,
+// Unwrap() []error and Cause() error, so the branches of errors created by Join are visited in order.
+// Walk stops as soon as fn returns false. An error already being visited on the current path is not
+// visited again, so that chains containing cycles terminate.
+//
+//	errors.Walk(err, func(layer error, depth int) bool {
+//	       fmt.Printf("%s%v\n", strings.Repeat("  ", depth), layer)
+//	       return true
+//	})
+func Walk(err error, fn func(layer error, depth int) bool) {
+	walkPath(err, 0, nil, func(layer error, depth int) bool {
+		// the errors of goroutines launched by Group.Go are not layers of their own
+		if _, ok := layer.(*spawnError); ok {
+			return true
+		}
+		return fn(layer, depth)
+	})
+}
+
+// walk calls fn for e and every error reachable from it, depth first and outermost first.
+// The chain is followed through Unwrap() error, Unwrap() []error and Cause() error.
+// walk stops as soon as fn returns false and reports whether the walk was stopped.
+func walk(e error, fn func(error) bool) bool {
+	return walkPath(e, 0, nil, func(err error, _ int) bool { return fn(err) })
+}
+
+// walkPath walks e at the given depth, path holds the errors between the outermost error and e.
+// It reports whether the walk was stopped by fn.
+func walkPath(e error, depth int, path []error, fn func(error, int) bool) bool {
+	if e == nil || depth >= maxWalkDepth || onPath(path, e) {
+		return false
+	}
+	if !fn(e, depth) {
+		return true
+	}
+	path = append(path, e)
+	switch x := e.(type) {
+	case *spawnError:
+		// see Walk, the error returned by the goroutine is at the same depth
+		return walkPath(x.err, depth, path, fn)
+	case interface{ Unwrap() error }:
+		return walkPath(x.Unwrap(), depth+1, path, fn)
+	case interface{ Unwrap() []error }:
+		for _, err := range x.Unwrap() {
+			if walkPath(err, depth+1, path, fn) {
+				return true
+			}
+		}
+	case interface{ Cause() error }:
+		return walkPath(x.Cause(), depth+1, path, fn)
+	}
+	return false
+}
+
+// onPath reports whether e is one of the errors of path. Errors that cannot be compared are never on the path.
+func onPath(path []error, e error) bool {
+	if !reflect.TypeOf(e).Comparable() {
+		return false
+	}
+	for _, err := range path {
+		if err == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Layers returns a description of every error reachable from e, in the order of Walk.
+// It returns nil if e is nil.
+func Layers(e error) []Layer {
+	var layers []Layer
+	Walk(e, func(err error, depth int) bool {
+		layer := Layer{
+			Error: err,
+			Depth: depth,
+			Code:  UnknownCode,
+			Msg:   Msg(err),
+		}
+		if c, ok := err.(Coder); ok {
+			layer.Code = c.Code()
+		}
+		if k, ok := err.(Kinder); ok {
+			layer.Kind = k.Kind()
+		}
+		if b, ok := err.(*baseError); ok && b != nil {
+			layer.Location = b.Location()
+			layer.Fields = append([]Field(nil), b.fields...)
+		}
+		layers = append(layers, layer)
+		return true
+	})
+	return layers
+}
+
+// Codes returns the codes carried by the errors reachable from e, in the order of Walk.
+// Errors that do not implement Coder, or carry the code 0 or UnknownCode, are skipped,
+// as are errors created by Join, whose code is the code of one of their branches.
+func Codes(e error) []int {
+	var codes []int
+	Walk(e, func(err error, _ int) bool {
+		if _, ok := err.(*joinError); ok {
+			return true
+		}
+		if c, ok := err.(Coder); ok && c.Code() != 0 && c.Code() != UnknownCode {
+			codes = append(codes, c.Code())
+		}
+		return true
+	})
+	return codes
+}
+
+// Find returns the first error reachable from e, in the order of Walk, for which predicate returns true.
+// It returns nil if there is none.
+//
+//	notFound := errors.Find(err, func(err error) bool {
+//	       return errors.Code(err) == 40401
+//	})
+func Find(e error, predicate func(error) bool) error {
+	var found error
+	Walk(e, func(err error, _ int) bool {
+		if predicate(err) {
+			found = err
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// Depth returns the number of errors on the longest path from e to the innermost errors of its chain,
+// e.g. 1 for an error that wraps nothing. It returns 0 if e is nil.
+func Depth(e error) int {
+	depth := 0
+	Walk(e, func(_ error, d int) bool {
+		if d+1 > depth {
+			depth = d + 1
+		}
+		return true
+	})
+	return depth
+}
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cyclicErr struct {
+	next *cyclicErr
+}
+
+func (c *cyclicErr) Error() string { return "cyclic" }
+func (c *cyclicErr) Unwrap() error { return c.next }
+
+func TestWalk(t *testing.T) {
+	err := Wrap(Join(NewWithCode(40401, "no such user"), fmt.Errorf("read: %w", io.EOF)), "load users")
+
+	var visited []string
+	Walk(err, func(layer error, depth int) bool {
+		visited = append(visited, fmt.Sprintf("%d %T", depth, layer))
+		return true
+	})
+	assert.Equal(t, []string{
+		"0 *errors.baseError",
+		"1 *errors.joinError",
+		"2 *errors.baseError",
+		"2 *fmt.wrapError",
+		"3 *errors.errorString",
+	}, visited)
+
+	visited = nil
+	Walk(err, func(layer error, depth int) bool {
+		visited = append(visited, layer.Error())
+		return depth < 1
+	})
+	assert.Len(t, visited, 2)
+
+	a := &cyclicErr{}
+	a.next = &cyclicErr{next: a}
+	count := 0
+	Walk(a, func(error, int) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, Depth(a))
+	assert.Equal(t, UnknownCode, Code(a))
+
+	// the same error in several branches is not a cycle
+	assert.Len(t, Layers(Join(io.EOF, io.EOF)), 3)
+}
+
+func TestLayers(t *testing.T) {
+	err := WrapWithFields(WrapWithKind(WrapWithCode(io.EOF, 40401, "read user"), KindNotFound, "lookup"),
+		"load user", "user_id", 42)
+	layers := Layers(err)
+	assert.Len(t, layers, 4)
+	assert.Equal(t, "load user", layers[0].Msg)
+	assert.Equal(t, []Field{{Key: "user_id", Value: 42}}, layers[0].Fields)
+	assert.Equal(t, 0, layers[0].Code)
+	assert.Equal(t, KindNotFound, layers[1].Kind)
+	assert.Equal(t, 40401, layers[2].Code)
+	assert.Equal(t, "walk_test.go", layers[2].Location.RelFile())
+	assert.Equal(t, Layer{Error: io.EOF, Depth: 3, Code: UnknownCode, Msg: "EOF"}, layers[3])
+	assert.Nil(t, Layers(nil))
+}
+
+func TestCodesFindDepth(t *testing.T) {
+	err := Wrap(Join(NewWithCode(40401, "no such user"), WrapWithCode(io.EOF, 40901, "exists")), "load users")
+	assert.Equal(t, []int{40401, 40901}, Codes(err))
+	assert.Nil(t, Codes(io.EOF))
+
+	found := Find(err, func(err error) bool { return Code(err) == 40901 })
+	assert.Equal(t, "40901, exists\nCaused by: EOF", found.Error())
+	assert.Nil(t, Find(err, func(err error) bool { return false }))
+
+	assert.Equal(t, 4, Depth(err))
+	assert.Equal(t, 1, Depth(io.EOF))
+	assert.Equal(t, 0, Depth(nil))
+}
@@ -0,0 +1,208 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// wireVersion is the version of the format written by Encode.
+const wireVersion = 1
+
+// wireEnvelope is the document written by Encode, the version allows the format to evolve.
+type wireEnvelope struct {
+	Version int        `json:"version"`
+	Error   *jsonError `json:"error"`
+}
+
+// Encode serializes the error chain of e so that it can be sent to another process and rebuilt by Decode.
+// Codes, messages, fields, locations and stacks of every layer are kept, stacks are always included
+// regardless of Config.JSONOmitStack. Foreign errors are described by their message and Go type.
+// Encode returns nil for a nil error.
+//
+//	w.Write(errors.Encode(err))
+func Encode(e error) []byte {
+	if e == nil {
+		return nil
+	}
+	data, err := json.Marshal(wireEnvelope{Version: wireVersion, Error: toJSONError(e, true)})
+	if err != nil {
+		// fields are made marshallable by jsonValue, keep at least the message
+		data, _ = json.Marshal(wireEnvelope{Version: wireVersion, Error: &jsonError{Message: e.Error()}})
+	}
+	return data
+}
+
+// Decode rebuilds an error chain serialized by Encode.
+// The errors of this package are rebuilt with their codes, messages, fields and locations, so that
+// Code, EffectiveCode, Msg, Fields and %+v work on the decoded error. Their stacks are marked as remote,
+// see StackTrace.Remote. Foreign errors are rebuilt as opaque errors keeping their message and code.
+// Sentinels registered by RegisterSentinel are restored as the sentinel values themselves, and errors of
+// types registered by RegisterType are rebuilt with their concrete type, so that Is and As keep working.
+// Decode returns nil for empty data or "null", and an opaque error holding the text of data
+// if data is not a document written by Encode.
+func Decode(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var envelope wireEnvelope
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep the precision of numbers in fields
+	decoder.UseNumber()
+	if err := decoder.Decode(&envelope); err != nil || envelope.Error == nil ||
+		envelope.Version < 1 || envelope.Version > wireVersion {
+		return &opaqueError{msg: string(data)}
+	}
+	return fromJSONError(envelope.Error)
+}
+
+// fromJSONError rebuilds the error described by doc and the errors below it.
+// An error returned by a goroutine launched by Group.Go keeps the stack of the Go call.
+func fromJSONError(doc *jsonError) error {
+	if doc == nil {
+		return nil
+	}
+	err := fromJSONLayer(doc)
+	if spawn := fromJSONFrames(doc.SpawnedBy); spawn != nil && err != nil {
+		return &spawnError{err: err, spawn: spawn}
+	}
+	return err
+}
+
+// fromJSONLayer rebuilds the error described by doc, the errors below it are rebuilt by fromJSONError.
+func fromJSONLayer(doc *jsonError) error {
+	if doc.Sentinel != "" {
+		if err, ok := sentinel(doc.Sentinel); ok {
+			return err
+		}
+	}
+	if doc.Registered != "" {
+		if err, ok := newOfType(doc.Registered, doc.Data); ok {
+			return err
+		}
+	}
+	var cause error
+	if doc.Cause != nil {
+		cause = fromJSONError(doc.Cause)
+	} else if len(doc.Errors) > 0 {
+		branches := make([]error, 0, len(doc.Errors))
+		for _, branch := range doc.Errors {
+			branches = append(branches, fromJSONError(branch))
+		}
+		cause = Join(branches...)
+	}
+
+	switch {
+	case doc.Type == "" && len(doc.Errors) > 0:
+		// errors created by Join carry no type
+		return cause
+	case doc.Type != "":
+		opaque := &opaqueError{msg: doc.Message, typ: doc.Type, cause: cause}
+		if doc.Code != 0 {
+			return &opaqueCoder{opaqueError: opaque, code: doc.Code}
+		}
+		return opaque
+	}
+
+	err := &baseError{
+		cause:  cause,
+		code:   doc.Code,
+		kind:   Kind(doc.Kind),
+		msg:    doc.Message,
+		inline: doc.Inline && cause != nil,
+		stack:  fromJSONFrames(doc.Stack),
+	}
+	if len(doc.Metadata) > 0 {
+		err.meta = doc.Metadata
+	}
+	if doc.Retryable != nil {
+		err.retry = &retryMark{retryable: *doc.Retryable, after: time.Duration(doc.RetryAfterMS) * time.Millisecond}
+	}
+	if doc.Location != nil {
+		location := fromJSONFrame(*doc.Location)
+		err.loc = &location
+	}
+	keys := make([]string, 0, len(doc.Fields))
+	for key := range doc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		err.fields = append(err.fields, Field{Key: key, Value: doc.Fields[key]})
+	}
+	return err
+}
+
+// fromJSONFrames rebuilds a remote stack, it returns nil if frames is empty.
+func fromJSONFrames(frames []jsonFrame) *StackTrace {
+	if len(frames) == 0 {
+		return nil
+	}
+	st := &StackTrace{remote: make([]Frame, 0, len(frames))}
+	for _, frame := range frames {
+		st.remote = append(st.remote, fromJSONFrame(frame))
+	}
+	return st
+}
+
+// fromJSONFrame rebuilds a Frame, remote frames have no program counter.
+func fromJSONFrame(frame jsonFrame) Frame {
+	if frame.Elided > 0 {
+		return Frame{elided: frame.Elided}
+	}
+	return Frame{frame: runtime.Frame{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}}
+}
+
+// opaqueError stands for a foreign error rebuilt by Decode, the original type is not available in this process.
+type opaqueError struct {
+	msg   string // msg is the message of the original error
+	typ   string // typ is the Go type of the original error, e.g. "*fs.PathError"
+	cause error  // cause is the rebuilt error wrapped by the original error
+}
+
+// Error returns the message of the original error.
+func (o *opaqueError) Error() string { return o.msg }
+
+// Unwrap supports Go 1.13+ error chains.
+func (o *opaqueError) Unwrap() error { return o.cause }
+
+// Format implements the Format interface for printing.
+// %+v prints the cause after the message only if it carries a stack, since the message already contains its text.
+func (o *opaqueError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, o.msg)
+			if hasStack(o.cause) {
+				_, _ = io.WriteString(s, GetCfg().ErrorConnectionFlag)
+				_, _ = fmt.Fprintf(s, "%+v", o.cause)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, o.msg)
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", o.msg)
+	default:
+		_, _ = fmt.Fprintf(s, "unsupported format: %%!%c, use %%s: %s", verb, o.msg)
+	}
+}
+
+// opaqueCoder is an opaqueError whose original error implemented Coder.
+type opaqueCoder struct {
+	*opaqueError
+	code int
+}
+
+// Code returns the code of the original error, it implements the Coder interface.
+func (o *opaqueCoder) Code() int { return o.code }
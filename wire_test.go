@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type wireCodeErr struct{}
+
+func (wireCodeErr) Error() string { return "coded" }
+func (wireCodeErr) Code() int     { return 42 }
+
+func TestEncodeDecode(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+		JSONOmitStack:       true,
+	})
+	defer ResetCfg()
+
+	origin := WrapWithFields(WrapWithCode(io.EOF, 40401, "read user"), "load user", "user_id", 42)
+	data := Encode(origin)
+	assert.Contains(t, string(data), `"version":1`)
+	// stacks are always encoded
+	assert.Contains(t, string(data), `"stack"`)
+
+	err := Decode(data)
+	assert.Equal(t, origin.Error(), err.Error())
+	assert.Equal(t, "EOF", Cause(err).Error())
+	assert.Equal(t, 40401, EffectiveCode(err))
+	assert.True(t, IsCode(err, 40401))
+	assert.Equal(t, "load user", Msg(err))
+	assert.Equal(t, json.Number("42"), Fields(err)["user_id"])
+	assert.False(t, Is(err, io.EOF))
+
+	st := err.(*baseError).StackTrace()
+	assert.True(t, st.Remote())
+	assert.Equal(t, "github.com/morrisxyang/errors.TestEncodeDecode", st.Frames()[0].Function())
+	assert.Equal(t, origin.(*baseError).Location().Line(), err.(*baseError).Location().Line())
+	assert.Regexp(t, "^load user \\(wire_test.go:\\d+\\): 40401 USER_NOT_FOUND, read user \\(wire_test.go:\\d+\\): EOF\n"+
+		"\\(remote\\)\ngithub.com/morrisxyang/errors.TestEncodeDecode\n\t.+/wire_test.go:\\d+$", fmt.Sprintf("%+v", err))
+
+	// re-encoding keeps the type of foreign errors
+	assert.Contains(t, string(Encode(err)), `"type":"*errors.errorString"`)
+
+	assert.Nil(t, Encode(nil))
+	assert.Nil(t, Decode(nil))
+	assert.Nil(t, Decode([]byte(" null ")))
+}
+
+func TestDecodeForeign(t *testing.T) {
+	SetCfg(&Config{
+		StackDepth:          1,
+		ErrorConnectionFlag: ": ",
+	})
+	defer ResetCfg()
+
+	inner := Errorf("parse config: %w", io.ErrUnexpectedEOF)
+	origin := fmt.Errorf("startup: %w", Join(inner, wireCodeErr{}))
+	err := Decode(Encode(origin))
+	assert.Equal(t, origin.Error(), err.Error())
+	assert.Equal(t, 42, EffectiveCode(err))
+
+	assert.True(t, IsCode(err, 42))
+
+	var decoded *baseError
+	assert.True(t, As(err, &decoded))
+	assert.Equal(t, "parse config: unexpected EOF", decoded.Error())
+	assert.Regexp(t, "^startup: parse config: unexpected EOF\ncoded: 2 errors occurred: parse config: unexpected EOF \\(wire_test.go:\\d+\\)\n",
+		fmt.Sprintf("%+v", err))
+
+	err = Decode([]byte("upstream timeout"))
+	assert.Equal(t, "upstream timeout", err.Error())
+	assert.Equal(t, UnknownCode, Code(err))
+	unknown := `{"version":99,"error":{"message":"x"}}`
+	assert.Equal(t, unknown, Decode([]byte(unknown)).Error())
+}